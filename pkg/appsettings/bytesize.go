@@ -0,0 +1,99 @@
+package appsettings
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes that can be configured with decimal (e.g., "10MB") or
+// binary (e.g., "512MiB") unit suffixes. Values without a suffix are interpreted as bytes.
+type ByteSize uint64
+
+// Byte size units.
+const (
+	Byte ByteSize = 1
+
+	KB ByteSize = 1000 * Byte
+	MB ByteSize = 1000 * KB
+	GB ByteSize = 1000 * MB
+	TB ByteSize = 1000 * GB
+	PB ByteSize = 1000 * TB
+
+	KiB ByteSize = 1 << 10
+	MiB ByteSize = 1 << 20
+	GiB ByteSize = 1 << 30
+	TiB ByteSize = 1 << 40
+	PiB ByteSize = 1 << 50
+)
+
+// byteSizeUnits lists the supported unit suffixes, ordered from largest to smallest binary unit for formatting.
+//
+//nolint:gochecknoglobals // immutable lookup table
+var byteSizeUnits = []struct {
+	suffix string
+	size   ByteSize
+}{
+	{"PiB", PiB}, {"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB},
+	{"PB", PB}, {"TB", TB}, {"GB", GB}, {"MB", MB}, {"KB", KB},
+	{"B", Byte},
+}
+
+// ParseByteSize parses a size such as "512MiB", "10MB", "1.5GiB" or "1024".
+// Unit suffixes are case-insensitive and may be separated from the number by spaces.
+func ParseByteSize(s string) (ByteSize, error) {
+	text := strings.TrimSpace(s)
+	number, unit := text, Byte
+
+	for _, candidate := range byteSizeUnits {
+		if len(text) > len(candidate.suffix) && strings.EqualFold(text[len(text)-len(candidate.suffix):], candidate.suffix) {
+			number = strings.TrimSpace(text[:len(text)-len(candidate.suffix)])
+			unit = candidate.size
+			break
+		}
+	}
+
+	if n, err := strconv.ParseUint(number, 10, 64); err == nil {
+		if n > math.MaxUint64/uint64(unit) {
+			return 0, fmt.Errorf("byte size %q overflows", s)
+		}
+		return ByteSize(n) * unit, nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil || f < 0 || math.IsNaN(f) {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+
+	size := f * float64(unit)
+	if size >= math.MaxUint64 {
+		return 0, fmt.Errorf("byte size %q overflows", s)
+	}
+	return ByteSize(size), nil
+}
+
+// String formats the size using the largest binary unit that represents it exactly (e.g., "512MiB").
+func (b ByteSize) String() string {
+	for _, unit := range byteSizeUnits[:5] {
+		if b >= unit.size && b%unit.size == 0 {
+			return strconv.FormatUint(uint64(b/unit.size), 10) + unit.suffix
+		}
+	}
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
@@ -0,0 +1,85 @@
+package appsettings
+
+import (
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ByteSize
+	}{
+		{"1024", 1024},
+		{"0", 0},
+		{"512MiB", 512 * MiB},
+		{"10MB", 10 * MB},
+		{"10mb", 10 * MB},
+		{"1kB", KB},
+		{"4 KiB", 4 * KiB},
+		{"1.5GiB", GiB + GiB/2},
+		{"2TB", 2 * TB},
+		{"1PiB", PiB},
+		{"100B", 100},
+		{" 8GiB ", 8 * GiB},
+	}
+
+	for _, test := range tests {
+		result, err := ParseByteSize(test.input)
+		if err != nil {
+			t.Errorf("ParseByteSize(%q) returned error: %v", test.input, err)
+			continue
+		}
+		if result != test.expected {
+			t.Errorf("ParseByteSize(%q) = %d, expected %d", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestParseByteSize_Invalid(t *testing.T) {
+	tests := []string{"", "MiB", "ten MB", "-1MB", "10XB", "20000PiB", "1e30"}
+
+	for _, input := range tests {
+		if _, err := ParseByteSize(input); err == nil {
+			t.Errorf("ParseByteSize(%q) should return error", input)
+		}
+	}
+}
+
+func TestByteSize_String(t *testing.T) {
+	tests := []struct {
+		input    ByteSize
+		expected string
+	}{
+		{0, "0B"},
+		{100, "100B"},
+		{KiB, "1KiB"},
+		{512 * MiB, "512MiB"},
+		{10 * MB, "10000000B"},
+		{3 * GiB, "3GiB"},
+		{1536, "1536B"},
+	}
+
+	for _, test := range tests {
+		if result := test.input.String(); result != test.expected {
+			t.Errorf("ByteSize(%d).String() = %q, expected %q", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestByteSize_TextRoundTrip(t *testing.T) {
+	size := 256 * MiB
+
+	text, err := size.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText() returned error: %v", err)
+	}
+
+	var result ByteSize
+	if err := result.UnmarshalText(text); err != nil {
+		t.Fatalf("UnmarshalText() returned error: %v", err)
+	}
+
+	if result != size {
+		t.Errorf("Expected %d after round trip, got %d", size, result)
+	}
+}
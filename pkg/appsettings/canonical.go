@@ -0,0 +1,182 @@
+package appsettings
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// canonicalizer renames the keys of one config layer to the config keys of the matching fields of the
+// config type, so that keys differing only in case are merged into the same entry regardless of their source.
+// Relative paths of path fields are resolved against the directory of the layer.
+type canonicalizer struct {
+	schema *schema
+	// merged is the config merged from the previous layers, used to select union variants.
+	merged map[string]interface{}
+	// dir is the directory relative paths of the layer are resolved against, if not empty.
+	dir string
+	// singleElements wraps single values of slice and array fields into arrays with one element.
+	singleElements bool
+	// untypedScalars converts string values for the type of their field with typedTextValue.
+	untypedScalars bool
+	// warnings collects a message for each pair of keys within the layer that differ only in case.
+	warnings []string
+}
+
+// value canonicalizes raw against type t. Keys without a matching field are kept unchanged.
+// Keys of one object that differ only in case are merged with the exact match taking precedence.
+func (c *canonicalizer) value(raw interface{}, t reflect.Type, path []string) interface{} {
+	if t == nil {
+		return resolvePlainScalars(raw)
+	}
+
+	base := elemType(t)
+	t = indirectType(c.schema.selectVariant(base, raw, c.merged, path))
+	if object, ok := raw.(map[string]interface{}); ok && base.Kind() == reflect.Interface {
+		// Canonicalize the discriminator key of a union, as the variant may not declare it as a field
+		for key, elem := range object {
+			if discriminator, found := c.schema.discriminatorKey(base, key); found && key != discriminator {
+				delete(object, key)
+				if _, exists := object[discriminator]; !exists {
+					object[discriminator] = elem
+				}
+			}
+		}
+	}
+
+	if c.singleElements && isSingleElement(raw, t) {
+		raw = []interface{}{raw}
+	}
+
+	switch value := raw.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			return c.object(value, t, path)
+		case reflect.Map:
+			for key, elem := range value {
+				value[key] = c.value(elem, t.Elem(), append(slices.Clip(path), key))
+			}
+			return value
+		case reflect.Interface:
+			if c.untypedScalars {
+				for key, elem := range value {
+					value[key] = c.value(elem, anyType, append(slices.Clip(path), key))
+				}
+				return value
+			}
+		default:
+		}
+	case []interface{}:
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i, elem := range value {
+				value[i] = c.value(elem, t.Elem(), append(slices.Clip(path), strconv.Itoa(i)))
+			}
+			return value
+		case reflect.Map:
+			// Untyped formats create arrays for numeric keys (e.g., codes.404 in an INI file)
+			object := make(map[string]interface{}, len(value))
+			for i, elem := range value {
+				if elem != nil {
+					object[strconv.Itoa(i)] = elem
+				}
+			}
+			return c.value(object, t, path)
+		case reflect.Interface:
+			if c.untypedScalars {
+				for i, elem := range value {
+					value[i] = c.value(elem, anyType, append(slices.Clip(path), strconv.Itoa(i)))
+				}
+				return value
+			}
+		default:
+		}
+	case string:
+		if c.untypedScalars {
+			return typedTextValue(value, t, c.schema.lenientScalars)
+		}
+	case plainScalar:
+		return resolveYAMLScalar(string(value), t)
+	default:
+	}
+
+	// Plain scalars below values without a typed field are resolved by the YAML rules
+	return resolvePlainScalars(raw)
+}
+
+// object returns object with its keys renamed to the config keys of the matching fields of struct type t.
+func (c *canonicalizer) object(object map[string]interface{}, t reflect.Type, path []string) map[string]interface{} {
+	type entry struct {
+		key       string
+		canonical string
+		fieldType reflect.Type
+		path      bool
+	}
+
+	entries := make([]entry, 0, len(object))
+	for key := range object {
+		item := entry{key: key, canonical: key}
+		if field, ok := findField(t, key); ok {
+			item.canonical, _, _ = fieldKey(field)
+			item.fieldType = field.Type
+			item.path = isPathField(&field, field.Type)
+		}
+		entries = append(entries, item)
+	}
+
+	// Apply exact matches last so that they take precedence over keys differing in case
+	slices.SortFunc(entries, func(a, b entry) int {
+		if exactA, exactB := a.key == a.canonical, b.key == b.canonical; exactA != exactB {
+			if exactA {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a.key, b.key)
+	})
+
+	result := make(map[string]interface{}, len(object))
+	origins := make(map[string]string, len(object))
+	for _, item := range entries {
+		itemPath := append(slices.Clip(path), item.canonical)
+		value := c.value(object[item.key], item.fieldType, itemPath)
+		if item.path && c.dir != "" {
+			value = resolvePaths(value, c.dir)
+		}
+
+		if origin, duplicate := origins[item.canonical]; duplicate {
+			c.warnings = append(c.warnings, fmt.Sprintf("keys %q and %q of %q differ only in case, using %q",
+				origin, item.key, strings.Join(itemPath, argPathSeparator), item.key))
+			mergeMaps(result, map[string]interface{}{item.canonical: value})
+		} else {
+			result[item.canonical] = value
+		}
+		origins[item.canonical] = item.key
+	}
+
+	return result
+}
+
+//nolint:gochecknoglobals // immutable reflection type
+var anyType = reflect.TypeFor[interface{}]()
+
+// isSingleElement reports whether raw is a single value for slice or array type t, excluding strings
+// for byte slices and types with a dedicated decoder or unmarshaler.
+func isSingleElement(raw interface{}, t reflect.Type) bool {
+	if _, ok := raw.([]interface{}); ok || raw == nil {
+		return false
+	}
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return false
+	}
+	if _, ok := raw.(string); ok && t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return false
+	}
+	if _, found := lookupDecoder(t); found {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(jsonUnmarshalerType) && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
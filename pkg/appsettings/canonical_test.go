@@ -0,0 +1,103 @@
+package appsettings
+
+import (
+	"reflect"
+	"testing"
+)
+
+type CanonicalConfig struct {
+	DatabaseURL string                `json:"databaseURL"`
+	Upstreams   []Upstream            `json:"upstreams"`
+	Named       map[string]Upstream   `json:"named"`
+	Primary     Optional[Upstream]    `json:"primary"`
+	Extra       map[string]string     `json:"extra"`
+	Backup      *Secret[UpstreamAuth] `json:"backup"`
+}
+
+type UpstreamAuth struct {
+	UserName string `json:"userName"`
+}
+
+func TestCanonicalizeValue(t *testing.T) {
+	raw := map[string]interface{}{
+		"DATABASEURL": "postgres://localhost",
+		"UPSTREAMS":   []interface{}{map[string]interface{}{"HOST": "a"}},
+		"named":       map[string]interface{}{"Main": map[string]interface{}{"Port": 80}},
+		"primary":     map[string]interface{}{"host": "p"},
+		"extra":       map[string]interface{}{"Key": "value"},
+		"backup":      map[string]interface{}{"USERNAME": "admin"},
+		"unknown":     map[string]interface{}{"Nested": true},
+	}
+
+	c := &canonicalizer{schema: new(schema)}
+	result := c.value(raw, reflect.TypeFor[CanonicalConfig](), nil)
+
+	expected := map[string]interface{}{
+		"databaseURL": "postgres://localhost",
+		"upstreams":   []interface{}{map[string]interface{}{"host": "a"}},
+		"named":       map[string]interface{}{"Main": map[string]interface{}{"port": 80}},
+		"primary":     map[string]interface{}{"host": "p"},
+		"extra":       map[string]interface{}{"Key": "value"},
+		"backup":      map[string]interface{}{"userName": "admin"},
+		"unknown":     map[string]interface{}{"Nested": true},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected config %v, got %v", expected, result)
+	}
+
+	if len(c.warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", c.warnings)
+	}
+}
+
+func TestCanonicalizeValue_Duplicates(t *testing.T) {
+	raw := map[string]interface{}{
+		"Upstreams": []interface{}{
+			map[string]interface{}{"Port": 81, "port": 80, "HOST": "a"},
+		},
+		"Primary": map[string]interface{}{"host": "b"},
+		"primary": map[string]interface{}{"port": 443},
+	}
+
+	c := &canonicalizer{schema: new(schema)}
+	result := c.value(raw, reflect.TypeFor[CanonicalConfig](), nil)
+
+	expected := map[string]interface{}{
+		"upstreams": []interface{}{map[string]interface{}{"host": "a", "port": 80}},
+		"primary":   map[string]interface{}{"host": "b", "port": 443},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected config %v, got %v", expected, result)
+	}
+
+	expectedWarnings := []string{
+		`keys "Port" and "port" of "upstreams.0.port" differ only in case, using "port"`,
+		`keys "Primary" and "primary" of "primary" differ only in case, using "primary"`,
+	}
+	if !reflect.DeepEqual(c.warnings, expectedWarnings) {
+		t.Errorf("Expected warnings %v, got %v", expectedWarnings, c.warnings)
+	}
+}
+
+func TestIsSingleElement(t *testing.T) {
+	tests := []struct {
+		raw      interface{}
+		t        reflect.Type
+		expected bool
+	}{
+		{"a", reflect.TypeFor[[]string](), true},
+		{map[string]interface{}{"host": "a"}, reflect.TypeFor[[1]Upstream](), true},
+		{[]interface{}{"a"}, reflect.TypeFor[[]string](), false},
+		{nil, reflect.TypeFor[[]string](), false},
+		{"a", reflect.TypeFor[string](), false},
+		{"AQI=", reflect.TypeFor[[]byte](), false},
+	}
+
+	for _, tt := range tests {
+		if result := isSingleElement(tt.raw, tt.t); result != tt.expected {
+			t.Errorf("isSingleElement(%v, %v) = %v, expected %v", tt.raw, tt.t, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,613 @@
+package appsettings
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//nolint:gochecknoglobals // decoders are registered process-wide for types the application does not own
+var (
+	decodersMu sync.RWMutex
+	decoders   = make(map[reflect.Type]func(string) (reflect.Value, error))
+)
+
+// RegisterDecoder registers a function converting string values into values of type V.
+// It is used for types the application does not own (e.g., *url.URL or time.Location) and
+// takes precedence over json.Unmarshaler, encoding.TextUnmarshaler and the default decoding.
+// Registering a decoder for the same type again replaces the previous one.
+func RegisterDecoder[V any](decode func(string) (V, error)) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+
+	decoders[reflect.TypeFor[V]()] = func(value string) (reflect.Value, error) {
+		result, err := decode(value)
+		return reflect.ValueOf(&result).Elem(), err
+	}
+}
+
+// lookupDecoder returns the registered decoder for type t, if any.
+func lookupDecoder(t reflect.Type) (func(string) (reflect.Value, error), bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+
+	decode, ok := decoders[t]
+	return decode, ok
+}
+
+// rawDecoder is implemented by wrapper types of this package (e.g., Secret or Optional) that decode
+// raw config values, including null, into their wrapped value using the same rules as any other field.
+type rawDecoder interface {
+	decodeRaw(s *schema, raw interface{}, path string) error
+}
+
+//nolint:gochecknoglobals // immutable reflection types
+var (
+	rawDecoderType      = reflect.TypeFor[rawDecoder]()
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	durationType        = reflect.TypeFor[time.Duration]()
+)
+
+// decodeValue decodes a raw config value (as produced by the config sources) into v.
+// It follows encoding/json semantics and additionally honours registered decoders,
+// encoding.TextUnmarshaler and time.Duration strings. path is the key path used in error messages.
+func (s *schema) decodeValue(raw interface{}, v reflect.Value, path string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(rawDecoderType) {
+		return v.Addr().Interface().(rawDecoder).decodeRaw(s, raw, path)
+	}
+
+	if raw == nil {
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			v.SetZero()
+		default:
+		}
+		return nil
+	}
+
+	// Registered decoders have the highest precedence
+	if text, ok := scalarText(raw); ok {
+		if decode, found := lookupDecoder(v.Type()); found {
+			result, err := decode(text)
+			if err != nil {
+				return decodeError(path, err)
+			}
+			v.Set(result)
+			return nil
+		}
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return s.decodeValue(raw, v.Elem(), path)
+	}
+
+	if v.Type() == durationType {
+		return decodeDuration(raw, v, path)
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(jsonUnmarshalerType) {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return decodeError(path, err)
+		}
+		if err := v.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(data); err != nil {
+			return decodeError(path, err)
+		}
+		return nil
+	}
+
+	if text, ok := scalarText(raw); ok && v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+			return decodeError(path, err)
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		return s.decodeInterface(raw, v, path)
+	case reflect.Struct:
+		return s.decodeStruct(raw, v, path)
+	case reflect.Map:
+		return s.decodeMap(raw, v, path)
+	case reflect.Slice:
+		return s.decodeSlice(raw, v, path)
+	case reflect.Array:
+		return s.decodeArray(raw, v, path)
+	default:
+		if text, ok := raw.(string); ok && s.lenientScalars {
+			return decodeLenientScalar(text, v, path)
+		}
+		return decodeScalar(raw, v, path)
+	}
+}
+
+// decodeDuration decodes a duration string (e.g., "1h30m") or a number of nanoseconds into v.
+func decodeDuration(raw interface{}, v reflect.Value, path string) error {
+	text, ok := raw.(string)
+	if !ok {
+		return decodeScalar(raw, v, path)
+	}
+
+	duration, err := time.ParseDuration(text)
+	if err != nil {
+		return decodeError(path, err)
+	}
+	v.SetInt(int64(duration))
+	return nil
+}
+
+// decodeInterface decodes raw into the variant selected by the discriminator if v is a registered union,
+// and into an empty interface value using the same representation as encoding/json with UseNumber otherwise,
+// so numbers are kept as json.Number without losing precision.
+func (s *schema) decodeInterface(raw interface{}, v reflect.Value, path string) error {
+	if _, ok := s.unions[v.Type()]; ok {
+		return s.decodeUnion(raw, v, path)
+	}
+
+	if v.NumMethod() != 0 {
+		return typeError(path, raw, v.Type())
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return decodeError(path, err)
+	}
+
+	var result interface{}
+	if err := unmarshalJSON(data, &result); err != nil {
+		return decodeError(path, err)
+	}
+
+	if result == nil {
+		v.SetZero()
+		return nil
+	}
+	v.Set(reflect.ValueOf(result))
+	return nil
+}
+
+// decodeUnion decodes a raw object into a new value of the variant selected by its discriminator and stores it in v.
+func (s *schema) decodeUnion(raw interface{}, v reflect.Value, path string) error {
+	object, ok := raw.(map[string]interface{})
+	if !ok {
+		return typeError(path, raw, v.Type())
+	}
+
+	variantType, err := s.variantType(v.Type(), object)
+	if err != nil {
+		return decodeError(path, err)
+	}
+
+	variant := reflect.New(variantType).Elem()
+	if err := s.decodeValue(raw, variant, path); err != nil {
+		return err
+	}
+	v.Set(variant)
+
+	return nil
+}
+
+// decodeStruct decodes a raw object into the matching fields of struct v. Unknown keys are ignored.
+func (s *schema) decodeStruct(raw interface{}, v reflect.Value, path string) error {
+	object, ok := raw.(map[string]interface{})
+	if !ok {
+		return typeError(path, raw, v.Type())
+	}
+
+	// Sort keys so that keys matching the same field are applied deterministically
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		info, found := lookupStructField(v.Type(), key)
+		if !found {
+			continue
+		}
+
+		field, settable := fieldByIndexAlloc(v, info.index)
+		if !settable {
+			continue
+		}
+
+		value := object[key]
+		if text, ok := value.(string); ok && info.quoted {
+			unquoted, err := unquoteValue(text)
+			if err != nil {
+				return decodeError(joinPath(path, key), err)
+			}
+			value = unquoted
+		}
+
+		if err := s.decodeValue(value, field, joinPath(path, key)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unquoteValue decodes the value of a field with the ",string" tag option from text holding a JSON
+// string, bool, number or null, like in encoding/json.
+func unquoteValue(text string) (interface{}, error) {
+	var value interface{}
+	if err := unmarshalJSON([]byte(text), &value); err != nil {
+		return nil, fmt.Errorf("invalid use of ,string struct tag, trying to decode %q", text)
+	}
+
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return nil, fmt.Errorf("invalid use of ,string struct tag, trying to decode %q", text)
+	default:
+		return value, nil
+	}
+}
+
+// fieldByIndexAlloc returns the nested field of struct v for the index sequence, allocating nil embedded pointers.
+// It reports false if an embedded pointer to an unexported struct type cannot be allocated.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, fieldPosition := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(fieldPosition)
+	}
+	return v, true
+}
+
+// decodeMap decodes a raw object into map v, merging into existing entries.
+func (s *schema) decodeMap(raw interface{}, v reflect.Value, path string) error {
+	object, ok := raw.(map[string]interface{})
+	if !ok {
+		return typeError(path, raw, v.Type())
+	}
+
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(v.Type(), len(object)))
+	}
+
+	keyType := v.Type().Key()
+	for key, value := range object {
+		mapKey, err := decodeMapKey(key, keyType)
+		if err != nil {
+			return decodeError(joinPath(path, key), err)
+		}
+
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := s.decodeValue(value, elem, joinPath(path, key)); err != nil {
+			return err
+		}
+		v.SetMapIndex(mapKey, elem)
+	}
+
+	return nil
+}
+
+// decodeMapKey converts an object key into a value of the given map key type.
+func decodeMapKey(key string, keyType reflect.Type) (reflect.Value, error) {
+	if reflect.PointerTo(keyType).Implements(textUnmarshalerType) {
+		mapKey := reflect.New(keyType)
+		if err := mapKey.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key)); err != nil {
+			return reflect.Value{}, err
+		}
+		return mapKey.Elem(), nil
+	}
+
+	switch keyType.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(key).Convert(keyType), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, keyType.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(n).Convert(keyType), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(key, 10, keyType.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(n).Convert(keyType), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported map key type %s", keyType)
+	}
+}
+
+// decodeSlice decodes a raw array into slice v of the same length, merging into existing elements like decodeArray.
+// A string is decoded into a byte slice as base64, like in encoding/json.
+func (s *schema) decodeSlice(raw interface{}, v reflect.Value, path string) error {
+	if text, ok := raw.(string); ok && v.Type().Elem().Kind() == reflect.Uint8 {
+		data, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return decodeError(path, err)
+		}
+		v.SetBytes(data)
+		return nil
+	}
+
+	array, ok := raw.([]interface{})
+	if !ok {
+		return typeError(path, raw, v.Type())
+	}
+
+	// Copy the existing elements so that the previous backing array is not modified
+	result := reflect.MakeSlice(v.Type(), len(array), len(array))
+	reflect.Copy(result, v)
+	if err := s.decodeElements(array, result, path); err != nil {
+		return err
+	}
+	v.Set(result)
+
+	return nil
+}
+
+// decodeArray decodes a raw array into array v. Surplus values are ignored, and elements without a value
+// in the array keep their value.
+func (s *schema) decodeArray(raw interface{}, v reflect.Value, path string) error {
+	array, ok := raw.([]interface{})
+	if !ok {
+		return typeError(path, raw, v.Type())
+	}
+
+	return s.decodeElements(array[:min(len(array), v.Len())], v, path)
+}
+
+// decodeElements decodes the values of a raw array into the elements of the slice or array v, which must be
+// at least as long. Elements with a nil value keep their value, as arrays set by index (e.g., from the
+// environment variable UPSTREAMS__1__PORT) hold nil for the elements before.
+func (s *schema) decodeElements(array []interface{}, v reflect.Value, path string) error {
+	for i, value := range array {
+		if value == nil {
+			continue
+		}
+		if err := s.decodeValue(value, v.Index(i), joinPath(path, strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeScalar decodes a raw string, bool or number into the scalar value v.
+func decodeScalar(raw interface{}, v reflect.Value, path string) error {
+	switch value := raw.(type) {
+	case string:
+		if v.Kind() == reflect.String {
+			v.SetString(value)
+			return nil
+		}
+	case bool:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(value)
+			return nil
+		}
+	case int:
+		return decodeInteger(int64(value), raw, v, path)
+	case float64:
+		return decodeFloat(value, raw, v, path)
+	case json.Number:
+		return decodeJSONNumber(value, v, path)
+	default:
+	}
+
+	return typeError(path, raw, v.Type())
+}
+
+// decodeJSONNumber sets the numeric value v from a number literal without losing integer precision.
+func decodeJSONNumber(number json.Number, v reflect.Value, path string) error {
+	text := number.String()
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(text, 10, 64)
+		if err == nil {
+			return decodeInteger(i, number, v, path)
+		}
+		if errors.Is(err, strconv.ErrRange) {
+			return overflowError(path, number, v.Type())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(text, 10, 64)
+		if err == nil {
+			if v.OverflowUint(u) {
+				return overflowError(path, number, v.Type())
+			}
+			v.SetUint(u)
+			return nil
+		}
+		if errors.Is(err, strconv.ErrRange) || strings.HasPrefix(text, "-") {
+			return overflowError(path, number, v.Type())
+		}
+	case reflect.Float32, reflect.Float64:
+	default:
+		return typeError(path, number, v.Type())
+	}
+
+	// Fall back to float syntax (e.g., 1.0 or 1e3), which must be integral for integer types
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return typeError(path, number, v.Type())
+	}
+	return decodeFloat(f, number, v, path)
+}
+
+// decodeInteger sets the numeric value v from an integer.
+func decodeInteger(i int64, raw interface{}, v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.OverflowInt(i) {
+			return overflowError(path, raw, v.Type())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if i < 0 || v.OverflowUint(uint64(i)) {
+			return overflowError(path, raw, v.Type())
+		}
+		v.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(i))
+	default:
+		return typeError(path, raw, v.Type())
+	}
+	return nil
+}
+
+// decodeFloat sets the numeric value v from a float, which must be integral for integer types.
+func decodeFloat(f float64, raw interface{}, v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f != math.Trunc(f) {
+			return typeError(path, raw, v.Type())
+		}
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			return overflowError(path, raw, v.Type())
+		}
+		return decodeInteger(int64(f), raw, v, path)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if f != math.Trunc(f) {
+			return typeError(path, raw, v.Type())
+		}
+		if f < 0 || f >= math.MaxUint64 || v.OverflowUint(uint64(f)) {
+			return overflowError(path, raw, v.Type())
+		}
+		v.SetUint(uint64(f))
+	case reflect.Float32, reflect.Float64:
+		if v.OverflowFloat(f) {
+			return overflowError(path, raw, v.Type())
+		}
+		v.SetFloat(f)
+	default:
+		return typeError(path, raw, v.Type())
+	}
+	return nil
+}
+
+// unmarshalJSON parses a JSON document into v, keeping numbers as json.Number so that
+// integers are not rounded to float64 before reaching the config type.
+func unmarshalJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		if err == nil {
+			err = errors.New("invalid data after top-level JSON value")
+		}
+		return err
+	}
+	return nil
+}
+
+// checkRawValue verifies that raw only consists of values produced by the config sources:
+// objects, arrays, strings, bools, numbers and null.
+func checkRawValue(raw interface{}, path string) error {
+	switch value := raw.(type) {
+	case nil, string, bool, int, float64, json.Number:
+		return nil
+	case map[string]interface{}:
+		for key, elem := range value {
+			if err := checkRawValue(elem, joinPath(path, key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		for i, elem := range value {
+			if err := checkRawValue(elem, joinPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return decodeError(path, fmt.Errorf("unsupported config value of type %T", raw))
+	}
+}
+
+// scalarText returns the textual form of a raw string, bool or number value.
+func scalarText(raw interface{}) (string, bool) {
+	switch value := raw.(type) {
+	case string:
+		return value, true
+	case plainScalar:
+		return string(value), true
+	case bool:
+		return strconv.FormatBool(value), true
+	case int:
+		return strconv.Itoa(value), true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case json.Number:
+		return value.String(), true
+	default:
+		return "", false
+	}
+}
+
+// joinPath appends key to the dotted key path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + argPathSeparator + key
+}
+
+// decodeError prefixes err with the key path, if any.
+func decodeError(path string, err error) error {
+	if path == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
+
+// typeError reports that raw cannot be decoded into type t.
+func typeError(path string, raw interface{}, t reflect.Type) error {
+	return decodeError(path, fmt.Errorf("cannot decode %s into %s", rawKind(raw), t))
+}
+
+// overflowError reports that the number raw does not fit into type t.
+func overflowError(path string, raw interface{}, t reflect.Type) error {
+	return decodeError(path, fmt.Errorf("number %v overflows %s", raw, t))
+}
+
+// rawKind describes the kind of a raw config value for error messages.
+func rawKind(raw interface{}) string {
+	switch raw.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case int, float64, json.Number:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", raw)
+	}
+}
@@ -0,0 +1,507 @@
+package appsettings
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+type LogLevel int
+
+const (
+	LogLevelInfo LogLevel = iota
+	LogLevelDebug
+)
+
+func (l *LogLevel) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "info":
+		*l = LogLevelInfo
+	case "debug":
+		*l = LogLevelDebug
+	default:
+		return errors.New("unknown log level " + string(text))
+	}
+	return nil
+}
+
+type DecoderConfig struct {
+	BindAddress net.IP         `json:"bindAddress"`
+	Level       LogLevel       `json:"level"`
+	Endpoint    *url.URL       `json:"endpoint"`
+	Pattern     *regexp.Regexp `json:"pattern"`
+	Location    *time.Location `json:"location"`
+	Started     time.Time      `json:"started"`
+}
+
+func init() {
+	RegisterDecoder(url.Parse)
+	RegisterDecoder(time.LoadLocation)
+}
+
+func TestLoad_TextUnmarshalerAndDecoders(t *testing.T) {
+	appSettings := New[DecoderConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithEnvVars([]string{
+			"BINDADDRESS=10.0.0.1",
+			"LEVEL=debug",
+			"LOCATION=UTC",
+			"STARTED=2024-01-02T03:04:05Z",
+		}).
+		WithArgs([]string{
+			"program",
+			"--endpoint", "https://example.com/api",
+			"--pattern", "^v[0-9]+$",
+		})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if !result.BindAddress.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("Expected bind address 10.0.0.1, got %v", result.BindAddress)
+	}
+
+	if result.Level != LogLevelDebug {
+		t.Errorf("Expected level %v, got %v", LogLevelDebug, result.Level)
+	}
+
+	if result.Endpoint == nil || result.Endpoint.Host != "example.com" || result.Endpoint.Path != "/api" {
+		t.Errorf("Expected endpoint https://example.com/api, got %v", result.Endpoint)
+	}
+
+	if result.Pattern == nil || !result.Pattern.MatchString("v12") {
+		t.Errorf("Expected pattern ^v[0-9]+$, got %v", result.Pattern)
+	}
+
+	if result.Location != time.UTC {
+		t.Errorf("Expected location UTC, got %v", result.Location)
+	}
+
+	if !result.Started.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Expected started 2024-01-02T03:04:05Z, got %v", result.Started)
+	}
+}
+
+// ZipCode is decoded by a decoder registered for its pointer type.
+type ZipCode struct {
+	Code string
+}
+
+func TestLoad_PointerDecoderReceivesSourceText(t *testing.T) {
+	RegisterDecoder(func(text string) (*ZipCode, error) { return &ZipCode{Code: text}, nil })
+
+	type Config struct {
+		Zip    *ZipCode           `json:"zip"`
+		Backup Optional[*ZipCode] `json:"backup"`
+	}
+
+	result, err := New[Config]().
+		WithConfigDirectory(t.TempDir()).
+		WithEnvVars([]string{"ZIP=01234"}).
+		WithArgs([]string{"--backup", "1.10"}).
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.Zip == nil || result.Zip.Code != "01234" {
+		t.Errorf("Expected zip code 01234, got %+v", result.Zip)
+	}
+	if backup, ok := result.Backup.Get(); !ok || backup == nil || backup.Code != "1.10" {
+		t.Errorf("Expected backup zip code 1.10, got %+v", result.Backup)
+	}
+}
+
+func TestLoad_DecoderErrors(t *testing.T) {
+	tests := []string{
+		"LEVEL=verbose",
+		"LOCATION=Nowhere/Unknown",
+		"ENDPOINT=://missing-scheme",
+	}
+
+	for _, envVar := range tests {
+		appSettings := New[DecoderConfig]().
+			WithConfigDirectory(t.TempDir()).
+			WithEnvVars([]string{envVar})
+
+		if _, err := appSettings.Load(); err == nil {
+			t.Errorf("Load() with %s should return error", envVar)
+		}
+	}
+}
+
+type Region string
+
+type EmbeddedDecodeBase struct {
+	ID string `json:"id"`
+}
+
+type DecodeConfig struct {
+	*EmbeddedDecodeBase
+	Int     int8              `json:"int"`
+	Uint    uint16            `json:"uint"`
+	Float   float32           `json:"float"`
+	Regions []Region          `json:"regions"`
+	Pair    [2]int            `json:"pair"`
+	Weights map[int]float64   `json:"weights"`
+	Labels  map[string]string `json:"labels"`
+	Any     interface{}       `json:"any"`
+	Data    []byte            `json:"data"`
+	Pointer *int              `json:"pointer"`
+	Skipped string            `json:"-"`
+}
+
+func TestDecodeValue(t *testing.T) {
+	raw := map[string]interface{}{
+		"id":      "base",
+		"int":     float64(-12),
+		"uint":    42,
+		"float":   1.5,
+		"regions": []interface{}{"eu", "us"},
+		"pair":    []interface{}{float64(1)},
+		"weights": map[string]interface{}{"1": 0.5, "2": 2},
+		"labels":  map[string]interface{}{"team": "core"},
+		"any":     map[string]interface{}{"count": 3},
+		"data":    "aGVsbG8=",
+		"pointer": float64(7),
+		"Skipped": "ignored",
+		"unknown": "ignored",
+	}
+
+	var result DecodeConfig
+	if err := new(schema).decodeValue(raw, reflect.ValueOf(&result).Elem(), ""); err != nil {
+		t.Fatalf("decodeValue() returned error: %v", err)
+	}
+
+	seven := 7
+	expected := DecodeConfig{
+		EmbeddedDecodeBase: &EmbeddedDecodeBase{ID: "base"},
+		Int:                -12,
+		Uint:               42,
+		Float:              1.5,
+		Regions:            []Region{"eu", "us"},
+		Pair:               [2]int{1, 0},
+		Weights:            map[int]float64{1: 0.5, 2: 2},
+		Labels:             map[string]string{"team": "core"},
+		Any:                map[string]interface{}{"count": json.Number("3")},
+		Data:               []byte("hello"),
+		Pointer:            &seven,
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected config %+v, got %+v", expected, result)
+	}
+}
+
+func TestDecodeValue_Errors(t *testing.T) {
+	tests := []struct {
+		raw      map[string]interface{}
+		expected string
+	}{
+		{map[string]interface{}{"int": float64(300)}, "int: number 300 overflows int8"},
+		{map[string]interface{}{"int": 1.5}, "int: cannot decode number into int8"},
+		{map[string]interface{}{"uint": -1}, "uint: number -1 overflows uint16"},
+		{map[string]interface{}{"float": "fast"}, "float: cannot decode string into float32"},
+		{map[string]interface{}{"regions": "eu"}, "regions: cannot decode string into []appsettings.Region"},
+		{map[string]interface{}{"regions": []interface{}{true}}, "regions.0: cannot decode bool into appsettings.Region"},
+		{map[string]interface{}{"weights": map[string]interface{}{"one": 1}}, "weights.one: "},
+		{map[string]interface{}{"labels": []interface{}{}}, "labels: cannot decode array into map[string]string"},
+	}
+
+	for _, test := range tests {
+		var result DecodeConfig
+		err := new(schema).decodeValue(test.raw, reflect.ValueOf(&result).Elem(), "")
+		if err == nil || !strings.HasPrefix(err.Error(), test.expected) {
+			t.Errorf("decodeValue(%v) returned error %v, expected %q", test.raw, err, test.expected)
+		}
+	}
+}
+
+func TestDecodeValue_Null(t *testing.T) {
+	seven := 7
+	result := DecodeConfig{Int: 3, Pointer: &seven, Labels: map[string]string{"a": "b"}}
+
+	raw := map[string]interface{}{"int": nil, "pointer": nil, "labels": nil}
+	if err := new(schema).decodeValue(raw, reflect.ValueOf(&result).Elem(), ""); err != nil {
+		t.Fatalf("decodeValue() returned error: %v", err)
+	}
+
+	if result.Int != 3 || result.Pointer != nil || result.Labels != nil {
+		t.Errorf("Expected null to keep scalars and reset pointers and maps, got %+v", result)
+	}
+}
+
+type ShadowedInner struct {
+	Name  string `json:"name"`
+	Level int    `json:"level"`
+	Host  string
+}
+
+type ShadowedOther struct {
+	Level int `json:"level"`
+	Host  string
+}
+
+type ShadowedConfig struct {
+	ShadowedInner
+	*ShadowedOther
+	Name string `json:"name"`
+}
+
+type QuotedConfig struct {
+	Port    int     `json:"port,string"`
+	Ratio   float64 `json:"ratio,string"`
+	Enabled *bool   `json:"enabled,string"`
+	Label   string  `json:"label,string"`
+	Plain   string  `json:"plain"`
+}
+
+func TestDecodeValue_MatchesEncodingJSON(t *testing.T) {
+	tests := []struct {
+		data   string
+		target func() interface{}
+	}{
+		{`{"name": "outer", "level": 3, "Host": "h"}`, func() interface{} { return new(ShadowedConfig) }},
+		{`{"NAME": "outer"}`, func() interface{} { return new(ShadowedConfig) }},
+		{`{"port": "8080", "ratio": "1.5", "enabled": "true", "label": "\"a\"", "plain": "8080"}`,
+			func() interface{} { return new(QuotedConfig) }},
+	}
+
+	for _, test := range tests {
+		var raw map[string]interface{}
+		if err := unmarshalJSON([]byte(test.data), &raw); err != nil {
+			t.Fatalf("Failed to parse %s: %v", test.data, err)
+		}
+
+		expected := test.target()
+		if err := json.Unmarshal([]byte(test.data), expected); err != nil {
+			t.Fatalf("json.Unmarshal(%s) returned error: %v", test.data, err)
+		}
+
+		result := test.target()
+		if err := new(schema).decodeValue(raw, reflect.ValueOf(result).Elem(), ""); err != nil {
+			t.Fatalf("decodeValue(%s) returned error: %v", test.data, err)
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("decodeValue(%s) = %+v, expected %+v like encoding/json", test.data, result, expected)
+		}
+	}
+}
+
+func TestLoad_QuotedFields(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "config.json"), []byte(`{"port": "8080", "label": "not quoted"}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := New[QuotedConfig]().WithConfigDirectory(tempDir).Load()
+	if err == nil || !strings.Contains(err.Error(), `label: invalid use of ,string struct tag, trying to decode "not quoted"`) {
+		t.Errorf("Expected invalid ,string value error, got %v", err)
+	}
+
+	result, err := New[QuotedConfig]().WithConfigDirectory(t.TempDir()).WithEnvVars([]string{"PORT=9090"}).Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if result.Port != 9090 {
+		t.Errorf("Expected port from env var, got %d", result.Port)
+	}
+}
+
+func TestCheckRawValue(t *testing.T) {
+	valid := map[string]interface{}{
+		"a": []interface{}{nil, "s", true, 1, 1.5, map[string]interface{}{}},
+	}
+	if err := checkRawValue(valid, ""); err != nil {
+		t.Errorf("checkRawValue() returned error for valid value: %v", err)
+	}
+
+	invalid := map[string]interface{}{
+		"a": []interface{}{func() {}},
+	}
+	err := checkRawValue(invalid, "")
+	if err == nil || !strings.HasPrefix(err.Error(), "a.0: ") {
+		t.Errorf("checkRawValue() returned error %v, expected error for a.0", err)
+	}
+}
+
+type DurationConfig struct {
+	Timeout     time.Duration  `json:"timeout"`
+	Interval    time.Duration  `json:"interval"`
+	Legacy      time.Duration  `json:"legacy"`
+	Grace       *time.Duration `json:"grace"`
+	BufferSize  ByteSize       `json:"bufferSize"`
+	MaxBodySize ByteSize       `json:"maxBodySize"`
+	CacheSize   ByteSize       `json:"cacheSize"`
+}
+
+func TestLoad_DurationAndByteSize(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	baseData := []byte(`{"timeout": "30s", "legacy": 1000000, "bufferSize": "512MiB", "cacheSize": 4096}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	appSettings := New[DurationConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvVars([]string{"INTERVAL=1h30m", "MAXBODYSIZE=10MB"}).
+		WithArgs([]string{"program", "--grace", "250ms"})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	grace := 250 * time.Millisecond
+	expected := &DurationConfig{
+		Timeout:     30 * time.Second,
+		Interval:    90 * time.Minute,
+		Legacy:      time.Millisecond,
+		Grace:       &grace,
+		BufferSize:  512 * MiB,
+		MaxBodySize: 10 * MB,
+		CacheSize:   4096,
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected config %+v, got %+v", expected, result)
+	}
+}
+
+func TestLoad_InvalidDurationAndByteSize(t *testing.T) {
+	tests := []string{"TIMEOUT=30 seconds", "BUFFERSIZE=lots"}
+
+	for _, envVar := range tests {
+		appSettings := New[DurationConfig]().
+			WithConfigDirectory(t.TempDir()).
+			WithEnvVars([]string{envVar})
+
+		if _, err := appSettings.Load(); err == nil {
+			t.Errorf("Load() with %s should return error", envVar)
+		}
+	}
+}
+
+type PrecisionConfig struct {
+	ID       int64   `json:"id"`
+	Bitmask  uint64  `json:"bitmask"`
+	Small    int8    `json:"small"`
+	Counter  uint32  `json:"counter"`
+	Ratio    float64 `json:"ratio"`
+	Rounded  int     `json:"rounded"`
+	Negative uint    `json:"negative"`
+}
+
+func TestLoad_PreservesIntegerPrecision(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	baseData := []byte(`{"id": 9007199254740993, "ratio": 0.25, "rounded": 1e3}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	appSettings := New[PrecisionConfig]().
+		WithConfigDirectory(tempDir).
+		WithConfigEnvVar("APP_CONFIG_JSON").
+		WithEnvVars([]string{
+			`APP_CONFIG_JSON={"counter": 4294967295}`,
+			"BITMASK=18446744073709551615",
+		})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := &PrecisionConfig{
+		ID:      9007199254740993,
+		Bitmask: 18446744073709551615,
+		Counter: 4294967295,
+		Ratio:   0.25,
+		Rounded: 1000,
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected config %+v, got %+v", expected, result)
+	}
+}
+
+func TestLoad_PreservesIntegerPrecisionInAnyFields(t *testing.T) {
+	type Config struct {
+		ID     any            `json:"id"`
+		Extra  map[string]any `json:"extra"`
+		Values []any          `json:"values"`
+	}
+
+	tempDir := t.TempDir()
+	baseData := []byte(`{"id": 9007199254740993, "extra": {"big": 18446744073709551615}, "values": [1.5, 9007199254740993]}`)
+	if err := os.WriteFile(filepath.Join(tempDir, "config.json"), baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	result, err := New[Config]().WithConfigDirectory(tempDir).Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := &Config{
+		ID:     json.Number("9007199254740993"),
+		Extra:  map[string]any{"big": json.Number("18446744073709551615")},
+		Values: []any{json.Number("1.5"), json.Number("9007199254740993")},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected config %+v, got %+v", expected, result)
+	}
+}
+
+func TestLoad_IntegerOverflow(t *testing.T) {
+	tests := []string{
+		`{"small": 128}`,
+		`{"counter": 4294967296}`,
+		`{"id": 9223372036854775808}`,
+		`{"bitmask": 18446744073709551616}`,
+		`{"negative": -1}`,
+		`{"rounded": 1.5}`,
+	}
+
+	for _, content := range tests {
+		tempDir := t.TempDir()
+		baseConfigFile := filepath.Join(tempDir, "config.json")
+		if err := os.WriteFile(baseConfigFile, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write base config: %v", err)
+		}
+
+		appSettings := New[PrecisionConfig]().WithConfigDirectory(tempDir)
+		if _, err := appSettings.Load(); err == nil {
+			t.Errorf("Load() with %s should return error", content)
+		}
+	}
+}
+
+func TestUnmarshalJSON_TrailingData(t *testing.T) {
+	var result map[string]interface{}
+	if err := unmarshalJSON([]byte(`{"a": 1} {"b": 2}`), &result); err == nil {
+		t.Error("unmarshalJSON() should return error for trailing data")
+	}
+
+	if err := unmarshalJSON([]byte(`{"a": 1}`+"\n"), &result); err != nil {
+		t.Errorf("unmarshalJSON() returned error: %v", err)
+	}
+
+	if result["a"] != json.Number("1") {
+		t.Errorf("Expected json.Number 1, got %v (%T)", result["a"], result["a"])
+	}
+}
@@ -0,0 +1,278 @@
+package appsettings
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dotEnvFileName is the name of dotenv files, followed by the optional environment.
+const dotEnvFileName = ".env"
+
+// dotEnvParser parses dotenv documents into environment variable assignments.
+type dotEnvParser struct {
+	text string
+	pos  int
+	// lookup returns the value of a variable referenced by ${NAME} or $NAME.
+	lookup func(name string) (string, bool)
+}
+
+// decodeDotEnv decodes a dotenv document into NAME=value assignments in document order.
+// Lines have the form [export] NAME=value and may be blank or start with # for comments.
+// Values may be unquoted (trimmed, with an inline comment after whitespace and #), single-quoted
+// (literal) or double-quoted (with \n, \r, \t, \", \\ and \$ escapes); quoted values may span lines.
+// ${NAME}, ${NAME:-default} and $NAME are expanded in unquoted and double-quoted values from the variables
+// assigned earlier in the document, falling back to lookup. Unknown variables expand to "".
+func decodeDotEnv(data []byte, lookup func(name string) (string, bool)) ([]string, error) {
+	p := &dotEnvParser{
+		text: strings.ReplaceAll(strings.TrimPrefix(string(data), "\ufeff"), "\r\n", "\n"),
+	}
+
+	var (
+		assignments []string
+		assigned    = make(map[string]string)
+	)
+	p.lookup = func(name string) (string, bool) {
+		if value, ok := assigned[name]; ok {
+			return value, true
+		}
+		return lookup(name)
+	}
+
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.text) {
+			return assignments, nil
+		}
+
+		name, value, err := p.parseAssignment()
+		if err != nil {
+			return nil, err
+		}
+		assigned[name] = value
+		assignments = append(assignments, name+"="+value)
+	}
+}
+
+// parseAssignment parses a single [export] NAME=value line.
+func (p *dotEnvParser) parseAssignment() (string, string, error) {
+	if rest, ok := strings.CutPrefix(p.text[p.pos:], "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+		p.pos += len("export")
+		p.skipSpaces()
+	}
+
+	start := p.pos
+	for p.pos < len(p.text) && isDotEnvNameByte(p.text[p.pos]) {
+		p.pos++
+	}
+	name := p.text[start:p.pos]
+	p.skipSpaces()
+	if name == "" || p.pos >= len(p.text) || p.text[p.pos] != '=' {
+		p.pos = start
+		return "", "", p.errorf("expected NAME=value, got %q", p.line())
+	}
+	p.pos++
+	p.skipSpaces()
+
+	var (
+		value string
+		err   error
+	)
+	switch {
+	case p.pos < len(p.text) && p.text[p.pos] == '\'':
+		value, err = p.parseSingleQuoted()
+	case p.pos < len(p.text) && p.text[p.pos] == '"':
+		value, err = p.parseDoubleQuoted()
+	default:
+		value, err = p.parseUnquoted()
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return name, value, p.endLine()
+}
+
+// parseSingleQuoted parses a literal single-quoted value.
+func (p *dotEnvParser) parseSingleQuoted() (string, error) {
+	end := strings.IndexByte(p.text[p.pos+1:], '\'')
+	if end < 0 {
+		return "", p.errorf("unterminated single-quoted value")
+	}
+	value := p.text[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	return value, nil
+}
+
+// parseDoubleQuoted parses a double-quoted value, resolving escapes and expanding variables.
+func (p *dotEnvParser) parseDoubleQuoted() (string, error) {
+	start := p.pos
+	p.pos++
+
+	var value strings.Builder
+	for p.pos < len(p.text) {
+		switch c := p.text[p.pos]; c {
+		case '"':
+			p.pos++
+			return value.String(), nil
+		case '\\':
+			if p.pos+1 < len(p.text) {
+				if escaped, ok := dotEnvEscape(p.text[p.pos+1]); ok {
+					value.WriteByte(escaped)
+					p.pos += 2
+					continue
+				}
+			}
+			value.WriteByte(c)
+			p.pos++
+		case '$':
+			expanded, err := p.expand()
+			if err != nil {
+				return "", err
+			}
+			value.WriteString(expanded)
+		default:
+			value.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	p.pos = start
+	return "", p.errorf("unterminated double-quoted value")
+}
+
+// parseUnquoted parses an unquoted value up to the end of the line or an inline comment and expands variables.
+func (p *dotEnvParser) parseUnquoted() (string, error) {
+	var value strings.Builder
+	for p.pos < len(p.text) && p.text[p.pos] != '\n' {
+		c := p.text[p.pos]
+		if c == '#' && (p.text[p.pos-1] == ' ' || p.text[p.pos-1] == '\t') {
+			break
+		}
+		if c == '$' {
+			expanded, err := p.expand()
+			if err != nil {
+				return "", err
+			}
+			value.WriteString(expanded)
+			continue
+		}
+		value.WriteByte(c)
+		p.pos++
+	}
+	return strings.TrimSpace(value.String()), nil
+}
+
+// expand expands the variable reference at the current position. A $ not followed by a name is kept.
+func (p *dotEnvParser) expand() (string, error) {
+	start := p.pos
+	p.pos++
+
+	if p.pos < len(p.text) && p.text[p.pos] == '{' {
+		end := strings.IndexByte(p.text[p.pos:], '}')
+		if end < 0 || strings.Contains(p.text[p.pos:p.pos+end], "\n") {
+			p.pos = start
+			return "", p.errorf("unterminated variable reference")
+		}
+		reference := p.text[p.pos+1 : p.pos+end]
+		p.pos += end + 1
+
+		name, fallback, hasFallback := strings.Cut(reference, ":-")
+		if !isDotEnvName(name) {
+			p.pos = start
+			return "", p.errorf("invalid variable reference ${%s}", reference)
+		}
+		if value, ok := p.lookup(name); ok && (value != "" || !hasFallback) {
+			return value, nil
+		}
+		return fallback, nil
+	}
+
+	nameStart := p.pos
+	for p.pos < len(p.text) && isDotEnvNameByte(p.text[p.pos]) && p.text[p.pos] != '.' && p.text[p.pos] != '-' {
+		p.pos++
+	}
+	if p.pos == nameStart {
+		return "$", nil
+	}
+	value, _ := p.lookup(p.text[nameStart:p.pos])
+	return value, nil
+}
+
+// endLine skips trailing whitespace and an optional comment, and fails on anything else before the end of the line.
+func (p *dotEnvParser) endLine() error {
+	p.skipSpaces()
+	if p.pos < len(p.text) && p.text[p.pos] == '#' {
+		for p.pos < len(p.text) && p.text[p.pos] != '\n' {
+			p.pos++
+		}
+	}
+	if p.pos < len(p.text) && p.text[p.pos] != '\n' {
+		return p.errorf("unexpected characters after value")
+	}
+	return nil
+}
+
+// skipBlank skips whitespace, new lines and comment lines.
+func (p *dotEnvParser) skipBlank() {
+	for p.pos < len(p.text) {
+		switch p.text[p.pos] {
+		case ' ', '\t', '\n':
+			p.pos++
+		case '#':
+			for p.pos < len(p.text) && p.text[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// skipSpaces skips spaces and tabs within a line.
+func (p *dotEnvParser) skipSpaces() {
+	for p.pos < len(p.text) && (p.text[p.pos] == ' ' || p.text[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// line returns the rest of the current line.
+func (p *dotEnvParser) line() string {
+	line, _, _ := strings.Cut(p.text[p.pos:], "\n")
+	return strings.TrimSpace(line)
+}
+
+// errorf returns an error annotated with the line of the current position.
+func (p *dotEnvParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("dotenv: line %d: %s", 1+strings.Count(p.text[:p.pos], "\n"), fmt.Sprintf(format, args...))
+}
+
+// isDotEnvName reports whether name is a valid variable name.
+func isDotEnvName(name string) bool {
+	for i := range len(name) {
+		if !isDotEnvNameByte(name[i]) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// isDotEnvNameByte reports whether c may appear in a variable name.
+func isDotEnvNameByte(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// dotEnvEscape returns the character denoted by the escape sequence \c in double-quoted values.
+func dotEnvEscape(c byte) (byte, bool) {
+	switch c {
+	case 'n':
+		return '\n', true
+	case 'r':
+		return '\r', true
+	case 't':
+		return '\t', true
+	case '"', '\\', '$':
+		return c, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,163 @@
+package appsettings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeDotEnv(t *testing.T) {
+	data := []byte(`# Local development settings
+NAME=my-app
+export PORT = 8080
+DEBUG=true # inline comment
+COLOR=#ff0000
+SINGLE='literal $NAME \n'
+DOUBLE="Hello\t\"$NAME\" \$5"
+MULTILINE="first
+second"
+KEY_FILE='-----BEGIN-----
+abc
+-----END-----'
+URL=postgres://${HOST:-localhost}:${DB_PORT}/db
+FALLBACK=${UNSET:-default}
+MISSING=$UNSET
+DOLLAR=costs $ 5
+EMPTY=
+`)
+
+	lookup := func(name string) (string, bool) {
+		if name == "DB_PORT" {
+			return "5432", true
+		}
+		return "", false
+	}
+
+	result, err := decodeDotEnv(data, lookup)
+	if err != nil {
+		t.Fatalf("decodeDotEnv() returned error: %v", err)
+	}
+
+	expected := []string{
+		"NAME=my-app",
+		"PORT=8080",
+		"DEBUG=true",
+		"COLOR=#ff0000",
+		`SINGLE=literal $NAME \n`,
+		"DOUBLE=Hello\t\"my-app\" $5",
+		"MULTILINE=first\nsecond",
+		"KEY_FILE=-----BEGIN-----\nabc\n-----END-----",
+		"URL=postgres://localhost:5432/db",
+		"FALLBACK=default",
+		"MISSING=",
+		"DOLLAR=costs $ 5",
+		"EMPTY=",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestDecodeDotEnv_Errors(t *testing.T) {
+	tests := map[string]string{
+		"NAME=a\njust a line":  `dotenv: line 2: expected NAME=value, got "just a line"`,
+		"=value":               `dotenv: line 1: expected NAME=value, got "=value"`,
+		"A=1\nB='open":         "dotenv: line 2: unterminated single-quoted value",
+		"A=\"open\nstill open": "dotenv: line 1: unterminated double-quoted value",
+		"A=${NAME":             "dotenv: line 1: unterminated variable reference",
+		"A=${NA ME}":           "dotenv: line 1: invalid variable reference ${NA ME}",
+		"A='quoted' trailing":  "dotenv: line 1: unexpected characters after value",
+	}
+
+	for data, expected := range tests {
+		_, err := decodeDotEnv([]byte(data), func(string) (string, bool) { return "", false })
+		if err == nil || err.Error() != expected {
+			t.Errorf("decodeDotEnv(%q) = %v, expected %q", data, err, expected)
+		}
+	}
+}
+
+func TestLoad_DotEnv(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"config.json":     `{"databaseURL": "postgres://localhost/json", "port": 8000, "name": "json"}`,
+		"config.dev.json": `{"debugMode": false}`,
+		".env":            "DATABASEURL=postgres://${DB_HOST}/dotenv\nPORT=8001\nNAME=dotenv\n",
+		".env.dev":        "export DEBUGMODE=true\nNAME=\"${NAME}-dev\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	result, err := New[TestConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvironment("dev").
+		WithEnvVars([]string{"DB_HOST=db", "PORT=9090"}).
+		WithDotEnv().
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.DatabaseURL != "postgres://db/dotenv" {
+		t.Errorf("Expected DatabaseURL from .env expanded with env var, got %q", result.DatabaseURL)
+	}
+	if result.Port != 9090 {
+		t.Errorf("Expected env var to override .env, got port %d", result.Port)
+	}
+	if !result.DebugMode || result.Name != "dotenv-dev" {
+		t.Errorf("Expected .env.dev to override .env and config files, got %+v", *result)
+	}
+}
+
+func TestLoad_DotEnvDisabled(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("PORT=8001\n"), 0600); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+
+	result, err := New[TestConfig]().WithConfigDirectory(tempDir).Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if result.Port != 0 {
+		t.Errorf("Expected .env to be ignored without WithDotEnv, got port %d", result.Port)
+	}
+}
+
+func TestLoad_DotEnvConfigEnvVar(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte(`APP_CONFIG_JSON='{"port": 8001, "name": "dotenv"}'`), 0600); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+
+	result, err := New[TestConfig]().
+		WithConfigDirectory(tempDir).
+		WithConfigEnvVar("APP_CONFIG_JSON").
+		WithEnvVars([]string{"NAME=env"}).
+		WithDotEnv().
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if result.Port != 8001 || result.Name != "env" {
+		t.Errorf("Expected config env var from .env overridden by env vars, got %+v", *result)
+	}
+}
+
+func TestLoad_InvalidDotEnv(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("PORT='8001"), 0600); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+
+	_, err := New[TestConfig]().WithConfigDirectory(tempDir).WithDotEnv().Load()
+	if err == nil || !strings.Contains(err.Error(), "failed to load dotenv") || !strings.Contains(err.Error(), "dotenv: line 1") {
+		t.Errorf("Expected dotenv parse error, got %v", err)
+	}
+}
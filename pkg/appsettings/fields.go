@@ -0,0 +1,295 @@
+package appsettings
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// configTagName is the struct tag defining config keys independently of the json tag.
+	configTagName = "config"
+	// configTagInline is the config tag option promoting the fields of a struct field into its parent.
+	configTagInline = "inline"
+	// stringTagOption is the tag option decoding the value of a field from a string holding it as JSON.
+	stringTagOption = "string"
+)
+
+// fieldKey returns the config key of a struct field, whether its fields are inlined into the parent
+// and whether the field is part of the config at all. A `config` tag takes precedence over the `json`
+// tag; without either, the Go field name is used and embedded structs are inlined like in encoding/json.
+func fieldKey(field reflect.StructField) (string, bool, bool) {
+	tag, hasConfigTag := field.Tag.Lookup(configTagName)
+	if !hasConfigTag {
+		tag = field.Tag.Get("json")
+	}
+	if tag == "-" {
+		return "", false, false
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+	inline := field.Anonymous && name == ""
+	if hasConfigTag && slices.Contains(strings.Split(options, ","), configTagInline) {
+		inline = true
+	}
+	inline = inline && indirectType(field.Type).Kind() == reflect.Struct
+
+	if !inline && !field.IsExported() {
+		return "", false, false
+	}
+
+	if name == "" {
+		name = field.Name
+	}
+	return name, inline, true
+}
+
+// indirectType dereferences pointer types until a non-pointer type is reached.
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// findField returns the field of struct type t whose config key matches name case-insensitively.
+// Fields of inlined structs are promoted into t.
+func findField(t reflect.Type, name string) (reflect.StructField, bool) {
+	index, ok := fieldIndex(t, name)
+	if !ok {
+		return reflect.StructField{}, false
+	}
+	return fieldByIndex(t, index), true
+}
+
+// fieldIndex returns the index sequence of the field of struct type t whose config key matches name.
+// An exact match is preferred over a case-insensitive one, like in encoding/json.
+func fieldIndex(t reflect.Type, name string) ([]int, bool) {
+	field, ok := lookupStructField(t, name)
+	return field.index, ok
+}
+
+// lookupStructField returns the field of struct type t whose config key matches name, preferring an exact match.
+func lookupStructField(t reflect.Type, name string) (structField, bool) {
+	fields := structFields(t)
+	for _, field := range fields {
+		if field.name == name {
+			return field, true
+		}
+	}
+	for _, field := range fields {
+		if strings.EqualFold(field.name, name) {
+			return field, true
+		}
+	}
+	return structField{}, false
+}
+
+// structField is a field of a struct type reachable by its config key, including fields promoted from inlined structs.
+type structField struct {
+	name   string
+	index  []int
+	tagged bool
+	// quoted reports that string values of the field hold its value encoded as JSON (the ",string" tag option).
+	quoted bool
+}
+
+//nolint:gochecknoglobals // cache of immutable type information
+var structFieldsCache sync.Map // map[reflect.Type][]structField
+
+// structFields returns the fields of struct type t reachable by their config keys in field order. Fields of
+// inlined structs are promoted into t, and fields with the same key are resolved like in encoding/json:
+// the shallowest field wins, then a field whose key is set by a tag, and if that is still ambiguous, none.
+func structFields(t reflect.Type) []structField {
+	if cached, ok := structFieldsCache.Load(t); ok {
+		return cached.([]structField)
+	}
+
+	type level struct {
+		t     reflect.Type
+		index []int
+	}
+
+	var (
+		fields  []structField
+		depths  = make(map[string]int)
+		visited = make(map[reflect.Type]bool)
+		next    = []level{{t: t}}
+	)
+	for depth := 0; len(next) > 0; depth++ {
+		current := next
+		next = nil
+		for _, parent := range current {
+			if visited[parent.t] {
+				continue
+			}
+			visited[parent.t] = true
+
+			for i := range parent.t.NumField() {
+				field := parent.t.Field(i)
+				name, inline, ok := fieldKey(field)
+				if !ok {
+					continue
+				}
+
+				index := append(slices.Clip(parent.index), i)
+				if inline {
+					next = append(next, level{t: indirectType(field.Type), index: index})
+					continue
+				}
+
+				// Fields hidden by a shallower field with the same key are dropped
+				if fieldDepth, exists := depths[name]; exists && fieldDepth < depth {
+					continue
+				}
+				depths[name] = depth
+				fields = append(fields, structField{name: name, index: index, tagged: hasTagKey(field), quoted: isQuoted(field)})
+			}
+		}
+	}
+
+	fields = dominantFields(fields)
+	slices.SortFunc(fields, func(a, b structField) int { return slices.Compare(a.index, b.index) })
+
+	cached, _ := structFieldsCache.LoadOrStore(t, fields)
+	return cached.([]structField)
+}
+
+// dominantFields keeps the dominant field for each config key of fields, which hold the fields of the
+// shallowest depth for each key: the only field, or the only one whose key is set by a tag.
+func dominantFields(fields []structField) []structField {
+	byName := make(map[string][]structField, len(fields))
+	for _, field := range fields {
+		byName[field.name] = append(byName[field.name], field)
+	}
+
+	result := make([]structField, 0, len(byName))
+	for _, candidates := range byName {
+		if len(candidates) == 1 {
+			result = append(result, candidates[0])
+			continue
+		}
+
+		tagged := slices.DeleteFunc(slices.Clone(candidates), func(field structField) bool { return !field.tagged })
+		if len(tagged) == 1 {
+			result = append(result, tagged[0])
+		}
+	}
+	return result
+}
+
+// isQuoted reports whether field has the ",string" tag option and a type it applies to, like in encoding/json:
+// a bool, numeric or string type or an unnamed pointer to one.
+func isQuoted(field reflect.StructField) bool {
+	tag, hasConfigTag := field.Tag.Lookup(configTagName)
+	if !hasConfigTag {
+		tag = field.Tag.Get("json")
+	}
+	_, options, _ := strings.Cut(tag, ",")
+	if !slices.Contains(strings.Split(options, ","), stringTagOption) {
+		return false
+	}
+
+	t := field.Type
+	if t.Name() == "" && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String || isLenientKind(t.Kind())
+}
+
+// hasTagKey reports whether the config key of field is set by its config or json tag.
+func hasTagKey(field reflect.StructField) bool {
+	tag, hasConfigTag := field.Tag.Lookup(configTagName)
+	if !hasConfigTag {
+		tag = field.Tag.Get("json")
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name != ""
+}
+
+// fieldByIndex returns the nested field of struct type t for the index sequence, following embedded pointers.
+func fieldByIndex(t reflect.Type, index []int) reflect.StructField {
+	field := t.Field(index[0])
+	for _, i := range index[1:] {
+		field = indirectType(field.Type).Field(i)
+	}
+	return field
+}
+
+// lookupField follows path through type t and returns the last struct field on the path.
+// Array, slice and map elements are traversed by index or key. It reports false if the path
+// does not lead through at least one struct field of t.
+func (s *schema) lookupField(t reflect.Type, path []string, merged map[string]interface{}) (reflect.StructField, bool) {
+	_, _, leaf, field := s.resolvePath(t, path, merged)
+	if leaf == nil || field == nil {
+		return reflect.StructField{}, false
+	}
+	return *field, true
+}
+
+// resolvePath follows path through type t and returns the path with keys renamed to the config keys
+// of the matching struct fields, the type each key is looked up in (e.g., a slice type for an index),
+// the type reached and the last struct field on the path. Union variants are selected by the
+// discriminators in merged. If the path leaves the fields of t, the remaining keys are kept unchanged
+// and their types as well as the type reached are nil.
+func (s *schema) resolvePath(
+	t reflect.Type, path []string, merged map[string]interface{},
+) ([]string, []reflect.Type, reflect.Type, *reflect.StructField) {
+	var (
+		canonical  = make([]string, len(path))
+		containers = make([]reflect.Type, len(path))
+		field      *reflect.StructField
+	)
+
+	copy(canonical, path)
+	for i, segment := range path {
+		if t == nil {
+			break
+		}
+
+		base := elemType(t)
+		if discriminator, ok := s.discriminatorKey(base, segment); ok {
+			canonical[i] = discriminator
+		}
+
+		t = s.selectVariant(base, nil, merged, canonical[:i])
+		containers[i] = indirectType(t)
+		switch indirectType(t).Kind() {
+		case reflect.Struct:
+			found, ok := findField(indirectType(t), segment)
+			if !ok {
+				t = nil
+				continue
+			}
+			canonical[i], _, _ = fieldKey(found)
+			field, t = &found, found.Type
+		case reflect.Array, reflect.Slice, reflect.Map:
+			t = indirectType(t).Elem()
+		default:
+			t = nil
+		}
+	}
+
+	return canonical, containers, t, field
+}
+
+// valueWrapper is implemented by wrapper types of this package (e.g., Secret or Optional)
+// to expose the type of the wrapped value.
+type valueWrapper interface {
+	wrappedType() reflect.Type
+}
+
+//nolint:gochecknoglobals // immutable reflection type
+var valueWrapperType = reflect.TypeFor[valueWrapper]()
+
+// elemType dereferences pointers and unwraps wrapper types until the type holding the config value is reached.
+func elemType(t reflect.Type) reflect.Type {
+	for {
+		t = indirectType(t)
+		if !t.Implements(valueWrapperType) {
+			return t
+		}
+		t = reflect.Zero(t).Interface().(valueWrapper).wrappedType()
+	}
+}
@@ -0,0 +1,88 @@
+package appsettings
+
+import (
+	"reflect"
+	"testing"
+)
+
+type EmbeddedFields struct {
+	Region string `json:"region"`
+}
+
+type LookupConfig struct {
+	EmbeddedFields
+	Upstreams []Upstream        `json:"upstreams"`
+	Labels    map[string]string `json:"labels"`
+	Ignored   string            `json:"-"`
+	Untagged  string
+	Pointer   *Upstream `json:"pointer,omitempty"`
+}
+
+type TaggedConfig struct {
+	Listen   Upstream `config:"listen" json:"server"`
+	Pool     Upstream `config:",inline"`
+	Internal string   `config:"-" json:"internal"`
+	Renamed  string   `config:"renamed"`
+	Fallback string   `config:""`
+}
+
+func TestFieldKey(t *testing.T) {
+	lookupType := reflect.TypeFor[LookupConfig]()
+	taggedType := reflect.TypeFor[TaggedConfig]()
+
+	tests := []struct {
+		configType reflect.Type
+		field      string
+		expected   string
+		inline     bool
+		ok         bool
+	}{
+		{lookupType, "EmbeddedFields", "EmbeddedFields", true, true},
+		{lookupType, "Upstreams", "upstreams", false, true},
+		{lookupType, "Ignored", "", false, false},
+		{lookupType, "Untagged", "Untagged", false, true},
+		{lookupType, "Pointer", "pointer", false, true},
+		{taggedType, "Listen", "listen", false, true},
+		{taggedType, "Pool", "Pool", true, true},
+		{taggedType, "Internal", "", false, false},
+		{taggedType, "Renamed", "renamed", false, true},
+		{taggedType, "Fallback", "Fallback", false, true},
+	}
+
+	for _, test := range tests {
+		field, _ := test.configType.FieldByName(test.field)
+		name, inline, ok := fieldKey(field)
+		if name != test.expected || inline != test.inline || ok != test.ok {
+			t.Errorf("fieldKey(%s) = (%q, %v, %v), expected (%q, %v, %v)",
+				test.field, name, inline, ok, test.expected, test.inline, test.ok)
+		}
+	}
+}
+
+func TestLookupField(t *testing.T) {
+	configType := reflect.TypeFor[LookupConfig]()
+
+	tests := []struct {
+		path     []string
+		expected string
+		ok       bool
+	}{
+		{[]string{"upstreams"}, "Upstreams", true},
+		{[]string{"upstreams", "0"}, "Upstreams", true},
+		{[]string{"upstreams", "0", "host"}, "Host", true},
+		{[]string{"labels", "team"}, "Labels", true},
+		{[]string{"region"}, "Region", true},
+		{[]string{"untagged"}, "Untagged", true},
+		{[]string{"pointer", "port"}, "Port", true},
+		{[]string{"ignored"}, "", false},
+		{[]string{"unknown"}, "", false},
+		{[]string{"upstreams", "0", "host", "extra"}, "", false},
+	}
+
+	for _, test := range tests {
+		field, ok := new(schema).lookupField(configType, test.path, nil)
+		if field.Name != test.expected || ok != test.ok {
+			t.Errorf("lookupField(%v) = (%q, %v), expected (%q, %v)", test.path, field.Name, ok, test.expected, test.ok)
+		}
+	}
+}
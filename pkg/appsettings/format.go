@@ -0,0 +1,108 @@
+package appsettings
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// configFileName is the base name of config files, followed by the optional environment and the extension.
+const configFileName = "config"
+
+// configFormat decodes the contents of config files with a given extension into a config map.
+type configFormat struct {
+	extension string
+	decode    func(data []byte) (map[string]interface{}, error)
+	// singleElements reports that the format cannot tell an array with a single element from a single value
+	// (e.g., a repeated XML element occurring once), so single values of slice and array fields are wrapped.
+	singleElements bool
+	// untypedScalars reports that the format has no scalar types besides strings (e.g., INI), so its values are
+	// converted for the type of their field instead of guessing the type from the value.
+	untypedScalars bool
+	// xmlAttributes reports that the format is the built-in XML format, whose attribute keys are prefixed
+	// as configured with WithXMLAttributePrefix.
+	xmlAttributes bool
+}
+
+// configFormats holds the registered config file formats. For the base and the environment-specific
+// config, the files of all formats that exist are loaded in this order, so later formats take precedence.
+// The built-in formats come first, followed by the formats added with RegisterFormat in registration order.
+//
+//nolint:gochecknoglobals // formats are registered process-wide like decoders
+var (
+	configFormatsMu sync.RWMutex
+	configFormats   = []configFormat{
+		{"json", decodeJSONCConfig, false, false, false},
+		{"yaml", decodeYAMLConfig, false, false, false},
+		{"yml", decodeYAMLConfig, false, false, false},
+		{"toml", decodeTOMLConfig, false, false, false},
+		{"ini", decodeINIConfig, false, true, false},
+		{"properties", decodePropertiesConfig, false, true, false},
+		{"xml", decodeXMLConfig, true, true, true},
+	}
+)
+
+// RegisterFormat registers a decoder for config files with extension ext (e.g., "hcl"), so that Load also
+// reads config.<ext> and config.<env>.<ext>. Files of newly registered formats are loaded after those of the
+// formats registered before, so they take precedence. Registering an extension again, including a built-in one,
+// replaces its decoder at its current position. Extensions are matched case-insensitively, ignoring a leading dot.
+// decode must return config values of the types produced by the built-in formats: nil, bool, string,
+// int, float64, json.Number, []any and map[string]any. It panics if ext is empty or decode is nil.
+func RegisterFormat(ext string, decode func([]byte) (map[string]any, error)) {
+	ext = strings.TrimPrefix(ext, ".")
+	if ext == "" {
+		panic("appsettings: RegisterFormat called with empty extension")
+	}
+	if decode == nil {
+		panic("appsettings: RegisterFormat called with nil decode function for extension " + ext)
+	}
+
+	configFormatsMu.Lock()
+	defer configFormatsMu.Unlock()
+
+	format := configFormat{extension: ext, decode: decode, singleElements: false, untypedScalars: false, xmlAttributes: false}
+	for i := range configFormats {
+		if strings.EqualFold(configFormats[i].extension, ext) {
+			format.extension = configFormats[i].extension
+			configFormats[i] = format
+			return
+		}
+	}
+	configFormats = append(configFormats, format)
+}
+
+// registeredFormats returns a snapshot of the registered config formats in load order.
+func registeredFormats() []configFormat {
+	configFormatsMu.RLock()
+	defer configFormatsMu.RUnlock()
+
+	return slices.Clone(configFormats)
+}
+
+// lookupFormat returns the config format for the extension of filePath, if registered.
+func lookupFormat(filePath string) (configFormat, bool) {
+	extension := strings.TrimPrefix(filepath.Ext(filePath), ".")
+	for _, format := range registeredFormats() {
+		if strings.EqualFold(format.extension, extension) {
+			return format, true
+		}
+	}
+	return configFormat{}, false
+}
+
+// configFilePaths returns the paths of the config files for environment (empty for the base config)
+// in dir, one per registered format in load order.
+func configFilePaths(dir, environment string) []string {
+	name := configFileName
+	if environment != "" {
+		name += "." + environment
+	}
+
+	formats := registeredFormats()
+	paths := make([]string, 0, len(formats))
+	for _, format := range formats {
+		paths = append(paths, filepath.Join(dir, name+"."+format.extension))
+	}
+	return paths
+}
@@ -0,0 +1,153 @@
+package appsettings
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigFilePaths(t *testing.T) {
+	dir := filepath.Join("etc", "app")
+
+	expectedBase := []string{
+		filepath.Join(dir, "config.json"),
+		filepath.Join(dir, "config.yaml"),
+		filepath.Join(dir, "config.yml"),
+		filepath.Join(dir, "config.toml"),
+		filepath.Join(dir, "config.ini"),
+		filepath.Join(dir, "config.properties"),
+		filepath.Join(dir, "config.xml"),
+	}
+	if result := configFilePaths(dir, ""); !reflect.DeepEqual(result, expectedBase) {
+		t.Errorf("Expected base paths %v, got %v", expectedBase, result)
+	}
+
+	expectedEnv := []string{
+		filepath.Join(dir, "config.dev.json"),
+		filepath.Join(dir, "config.dev.yaml"),
+		filepath.Join(dir, "config.dev.yml"),
+		filepath.Join(dir, "config.dev.toml"),
+		filepath.Join(dir, "config.dev.ini"),
+		filepath.Join(dir, "config.dev.properties"),
+		filepath.Join(dir, "config.dev.xml"),
+	}
+	if result := configFilePaths(dir, "dev"); !reflect.DeepEqual(result, expectedEnv) {
+		t.Errorf("Expected env paths %v, got %v", expectedEnv, result)
+	}
+}
+
+func TestLookupFormat(t *testing.T) {
+	tests := map[string]string{
+		"config.json":     "json",
+		"config.dev.YAML": "yaml",
+		"config.yml":      "yml",
+		"app.toml":        "toml",
+		"app.ini":         "ini",
+		"app.properties":  "properties",
+		"config.prod.xml": "xml",
+		"config.txt":      "",
+		"config":          "",
+	}
+
+	for path, expected := range tests {
+		format, ok := lookupFormat(path)
+		if format.extension != expected || ok != (expected != "") {
+			t.Errorf("lookupFormat(%q) = (%q, %v), expected %q", path, format.extension, ok, expected)
+		}
+	}
+}
+
+// restoreFormats restores the registered config formats after the test.
+func restoreFormats(t *testing.T) {
+	t.Helper()
+
+	formats := registeredFormats()
+	t.Cleanup(func() {
+		configFormatsMu.Lock()
+		defer configFormatsMu.Unlock()
+		configFormats = formats
+	})
+}
+
+func TestRegisterFormat(t *testing.T) {
+	restoreFormats(t)
+
+	decodeLines := func(data []byte) (map[string]any, error) {
+		result := make(map[string]any)
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			key, value, _ := strings.Cut(line, " ")
+			result[key] = value
+		}
+		return result, nil
+	}
+	RegisterFormat(".lines", decodeLines)
+
+	paths := configFilePaths("dir", "")
+	if last := paths[len(paths)-1]; last != filepath.Join("dir", "config.lines") {
+		t.Errorf("Expected registered format to be loaded last, got %v", paths)
+	}
+
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"config.json":  `{"databaseURL": "postgres://localhost/json", "name": "json"}`,
+		"config.lines": "name lines\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	result, err := New[TestConfig]().WithConfigDirectory(tempDir).Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if result.DatabaseURL != "postgres://localhost/json" || result.Name != "lines" {
+		t.Errorf("Expected registered format to override JSON file, got %+v", *result)
+	}
+}
+
+func TestRegisterFormat_ReplacesExisting(t *testing.T) {
+	restoreFormats(t)
+
+	errDecode := errors.New("custom json decoder")
+	RegisterFormat("JSON", func([]byte) (map[string]any, error) { return nil, errDecode })
+
+	if paths := configFilePaths("dir", ""); paths[0] != filepath.Join("dir", "config.json") {
+		t.Errorf("Expected replaced format to keep its position, got %v", paths)
+	}
+
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "config.json"), []byte(`{}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := New[TestConfig]().WithConfigDirectory(tempDir).Load()
+	if !errors.Is(err, errDecode) {
+		t.Errorf("Expected error of replaced decoder, got %v", err)
+	}
+}
+
+func TestRegisterFormat_Invalid(t *testing.T) {
+	restoreFormats(t)
+
+	decode := func([]byte) (map[string]any, error) { return nil, nil }
+	tests := map[string]func(){
+		"empty extension": func() { RegisterFormat(".", decode) },
+		"nil decode":      func() { RegisterFormat("hcl", nil) },
+	}
+
+	for name, register := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("RegisterFormat() should panic")
+				}
+			}()
+			register()
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package appsettings
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// decodeINIConfig decodes an INI config document. Sections map to nested objects, and dotted section
+// names and keys (e.g., [database.pool] or upstreams.0.host) to nested paths like command line arguments.
+// Values are kept as strings and converted for the type of their field when loaded; quotes preserve
+// surrounding whitespace, and double quotes support escape sequences. Lines starting with ; or # are comments.
+func decodeINIConfig(data []byte) (map[string]interface{}, error) {
+	config := make(map[string]interface{})
+	var section []string
+
+	for i, line := range strings.Split(strings.TrimPrefix(string(data), "\ufeff"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			name, ok := strings.CutSuffix(line, "]")
+			name = strings.TrimSpace(name[1:])
+			if !ok || name == "" {
+				return nil, fmt.Errorf("ini: line %d: invalid section header %q", i+1, line)
+			}
+			section = splitKeyPath(name, argPathSeparator)
+			continue
+		}
+
+		separator := strings.IndexAny(line, "=:")
+		if separator <= 0 {
+			return nil, fmt.Errorf("ini: line %d: expected key = value, got %q", i+1, line)
+		}
+
+		key := strings.TrimSpace(line[:separator])
+		value, err := parseINIValue(strings.TrimSpace(line[separator+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("ini: line %d: %w", i+1, err)
+		}
+
+		path := append(append([]string{}, section...), splitKeyPath(key, argPathSeparator)...)
+		if err := setPath(config, path, value); err != nil {
+			return nil, fmt.Errorf("ini: line %d: %w", i+1, err)
+		}
+	}
+
+	return config, nil
+}
+
+// parseINIValue returns an INI value with double or single quotes removed.
+func parseINIValue(value string) (string, error) {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted value %s", value)
+		}
+		return unquoted, nil
+	}
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return value[1 : len(value)-1], nil
+	}
+	return value, nil
+}
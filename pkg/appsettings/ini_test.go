@@ -0,0 +1,141 @@
+package appsettings
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeINIConfig(t *testing.T) {
+	data := []byte(`; Global settings
+name = my-app
+port: 8080
+debug = true
+quoted = "123"
+single = 'it''s'
+
+[database]
+host = localhost
+# pool settings
+pool.max = 10
+
+[upstreams.0]
+host = a
+port = 80
+`)
+
+	result, err := decodeINIConfig(data)
+	if err != nil {
+		t.Fatalf("decodeINIConfig() returned error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"name":   "my-app",
+		"port":   "8080",
+		"debug":  "true",
+		"quoted": "123",
+		"single": "it''s",
+		"database": map[string]interface{}{
+			"host": "localhost",
+			"pool": map[string]interface{}{"max": "10"},
+		},
+		"upstreams": []interface{}{
+			map[string]interface{}{"host": "a", "port": "80"},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestDecodeINIConfig_Errors(t *testing.T) {
+	tests := map[string]string{
+		"[database":             `ini: line 1: invalid section header "[database"`,
+		"\n[]":                  `ini: line 2: invalid section header "[]"`,
+		"name = a\njust a line": `ini: line 2: expected key = value, got "just a line"`,
+		"= value":               `ini: line 1: expected key = value, got "= value"`,
+		`name = "a\q"`:          `ini: line 1: invalid quoted value "a\q"`,
+	}
+
+	for data, expected := range tests {
+		_, err := decodeINIConfig([]byte(data))
+		if err == nil || err.Error() != expected {
+			t.Errorf("decodeINIConfig(%q) = %v, expected %q", data, err, expected)
+		}
+	}
+}
+
+func TestLoad_INIConfigFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"config.json":    `{"databaseURL": "postgres://localhost/json", "port": 8000}`,
+		"config.dev.ini": "port = 8001\nname = \"ini\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	result, err := New[TestConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvironment("dev").
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.DatabaseURL != "postgres://localhost/json" || result.Port != 8001 || result.Name != "ini" {
+		t.Errorf("Expected INI file to override JSON file, got %+v", *result)
+	}
+}
+
+func TestLoad_INIConfigFileTypedValues(t *testing.T) {
+	type Config struct {
+		Retries  int               `json:"retries"`
+		Debug    bool              `json:"debug"`
+		Password string            `json:"password"`
+		Ratio    float64           `json:"ratio"`
+		Extra    map[string]any    `json:"extra"`
+		Labels   map[string]string `json:"labels"`
+	}
+
+	tests := map[string]Config{
+		"retries = 0\ndebug = 0\npassword = 123456\n": {Password: "123456"},
+		"retries = 1\ndebug = 1\npassword = true\nratio = 1\n[extra]\nport = 8080\nname = a\n[labels]\nversion = 1.10\n": {
+			Retries: 1, Debug: true, Password: "true", Ratio: 1,
+			Extra: map[string]any{"port": json.Number("8080"), "name": "a"}, Labels: map[string]string{"version": "1.10"},
+		},
+	}
+
+	for content, expected := range tests {
+		tempDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(tempDir, "config.ini"), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+
+		result, err := New[Config]().WithConfigDirectory(tempDir).Load()
+		if err != nil {
+			t.Fatalf("Load(%q) returned error: %v", content, err)
+		}
+		if !reflect.DeepEqual(*result, expected) {
+			t.Errorf("Load(%q) = %+v, expected %+v", content, *result, expected)
+		}
+	}
+}
+
+func TestLoad_InvalidINIConfigFile(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "config.ini"), []byte("[database"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := New[TestConfig]().WithConfigDirectory(tempDir).Load()
+	if err == nil || !strings.Contains(err.Error(), "failed to load base config: ini: line 1") {
+		t.Errorf("Expected INI parse error, got %v", err)
+	}
+}
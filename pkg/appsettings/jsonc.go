@@ -0,0 +1,99 @@
+package appsettings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// stripJSONC replaces the comments (// and /* */) and trailing commas of a JSONC document with spaces,
+// so that it can be parsed as JSON while byte offsets, and thus line and column numbers, are preserved.
+func stripJSONC(data []byte) ([]byte, error) {
+	result := bytes.Clone(data)
+
+	// Blank out comments, keeping line breaks
+	inString := false
+	for i := 0; i < len(result); i++ {
+		switch c := result[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString || c != '/' || i+1 >= len(result):
+		case result[i+1] == '/':
+			for ; i < len(result) && result[i] != '\n'; i++ {
+				result[i] = ' '
+			}
+		case result[i+1] == '*':
+			end := bytes.Index(result[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, jsonPositionError(data, int64(i), errors.New("unterminated block comment"))
+			}
+			for j := i; j < i+2+end+2; j++ {
+				if result[j] != '\n' && result[j] != '\r' {
+					result[j] = ' '
+				}
+			}
+			i += 2 + end + 1
+		}
+	}
+
+	// Blank out commas between a value and a closing bracket
+	inString = false
+	for i := 0; i < len(result); i++ {
+		switch c := result[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case !inString && c == ',':
+			previous := bytes.TrimRight(result[:i], " \t\r\n")
+			next := bytes.TrimLeft(result[i+1:], " \t\r\n")
+			if len(previous) > 0 && !bytes.ContainsAny(previous[len(previous)-1:], "[{,") &&
+				len(next) > 0 && (next[0] == ']' || next[0] == '}') {
+				result[i] = ' '
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// decodeJSONCConfig decodes a JSON config document that may contain comments and trailing commas.
+// Syntax errors report the line and column of the offending character.
+func decodeJSONCConfig(data []byte) (map[string]interface{}, error) {
+	stripped, err := stripJSONC(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var config map[string]interface{}
+	if err := unmarshalJSON(stripped, &config); err != nil {
+		var syntaxError *json.SyntaxError
+		var typeError *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxError):
+			return nil, jsonPositionError(data, syntaxError.Offset-1, err)
+		case errors.As(err, &typeError):
+			// The document is not an object, which is detected at its first character
+			start := len(stripped) - len(bytes.TrimLeft(stripped, " \t\r\n"))
+			return nil, jsonPositionError(data, int64(start), err)
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return nil, jsonPositionError(data, int64(len(data)), err)
+		default:
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// jsonPositionError prefixes err with the 1-based line and column of the character at the byte offset in data.
+func jsonPositionError(data []byte, offset int64, err error) error {
+	offset = min(max(offset, 0), int64(len(data)))
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	column := 1 + utf8.RuneCount(data[bytes.LastIndexByte(data[:offset], '\n')+1:offset])
+	return fmt.Errorf("json: line %d, column %d: %w", line, column, err)
+}
@@ -0,0 +1,84 @@
+package appsettings
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStripJSONC(t *testing.T) {
+	data := []byte(`{
+  // line comment
+  "url": "http://example.com/*not a comment*/", /* block
+  comment */ "list": [1, 2,],
+  "escaped": "quote \" // still a string",
+}`)
+
+	stripped, err := stripJSONC(data)
+	if err != nil {
+		t.Fatalf("stripJSONC() returned error: %v", err)
+	}
+
+	if len(stripped) != len(data) || strings.Count(string(stripped), "\n") != strings.Count(string(data), "\n") {
+		t.Errorf("Expected offsets and line breaks to be preserved, got %q", stripped)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(stripped, &result); err != nil {
+		t.Fatalf("Expected valid JSON, got error %v for %q", err, stripped)
+	}
+
+	expected := map[string]interface{}{
+		"url":     "http://example.com/*not a comment*/",
+		"list":    []interface{}{float64(1), float64(2)},
+		"escaped": `quote " // still a string`,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestDecodeJSONCConfig_Errors(t *testing.T) {
+	tests := map[string]string{
+		"{\n  \"a\": 1\n  \"b\": 2\n}":   "json: line 3, column 3: invalid character '\"' after object key:value pair",
+		"{\n  // comment\n  \"a\": x\n}": "json: line 3, column 8: invalid character 'x' looking for beginning of value",
+		"{\"a\": \"é\", \"b\": ?}":       "json: line 1, column 17: invalid character '?' looking for beginning of value",
+		"{\n  \"a\": 1 /* open":          "json: line 2, column 10: unterminated block comment",
+		"{\n  \"a\": [1,\n":              "json: line 3, column 1: unexpected EOF",
+		"\n[1]":                          "json: line 2, column 1: json: cannot unmarshal array into Go value of type map[string]interface {}",
+		"{,}":                            "json: line 1, column 2: invalid character ',' looking for beginning of object key string",
+	}
+
+	for data, expected := range tests {
+		_, err := decodeJSONCConfig([]byte(data))
+		if err == nil || err.Error() != expected {
+			t.Errorf("decodeJSONCConfig(%q) = %v, expected %q", data, err, expected)
+		}
+	}
+}
+
+func TestLoad_JSONCConfigFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	baseData := []byte(`{
+    // Connection settings
+    "databaseURL": "postgres://localhost/my_app",
+    "port": 8080, /* default port */
+}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	result, err := New[TestConfig]().WithConfigDirectory(tempDir).Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.DatabaseURL != "postgres://localhost/my_app" || result.Port != 8080 {
+		t.Errorf("Expected values from JSONC file, got %+v", *result)
+	}
+}
@@ -0,0 +1,114 @@
+package appsettings
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// lenientBools maps the boolean spellings accepted in lenient mode, compared case-insensitively.
+//
+//nolint:gochecknoglobals // immutable lookup table
+var lenientBools = map[string]bool{
+	"true": true, "yes": true, "on": true, "y": true, "1": true,
+	"false": false, "no": false, "off": false, "n": false, "0": false,
+}
+
+// isLenientKind reports whether values of kind k are parsed with the lenient scalar grammar.
+func isLenientKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// isLenientType reports whether values of type t are decoded with the lenient scalar grammar,
+// i.e., t is a bool or numeric type without a dedicated decoder, unmarshaler or duration syntax.
+func isLenientType(t reflect.Type) bool {
+	if t == durationType || !isLenientKind(t.Kind()) {
+		return false
+	}
+	if _, found := lookupDecoder(t); found {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(jsonUnmarshalerType) && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// decodeLenientScalar decodes text into the bool or numeric value v using the lenient scalar grammar:
+// booleans may be written as yes/no, on/off, y/n or 1/0, and numbers may contain underscores and
+// 0x, 0o or 0b base prefixes. Decimal integers with leading zeros are rejected as ambiguous.
+// Values of other kinds are decoded like any other string.
+func decodeLenientScalar(text string, v reflect.Value, path string) error {
+	if !isLenientKind(v.Kind()) {
+		return decodeScalar(text, v, path)
+	}
+
+	trimmed := strings.TrimSpace(text)
+	if v.Kind() == reflect.Bool {
+		b, ok := lenientBools[strings.ToLower(trimmed)]
+		if !ok {
+			return decodeError(path, fmt.Errorf("invalid boolean %q, expected true/false, yes/no, on/off, y/n or 1/0", text))
+		}
+		v.SetBool(b)
+		return nil
+	}
+
+	if hasLeadingZero(trimmed) {
+		return decodeError(path, fmt.Errorf("ambiguous number %q, use the 0o prefix for octal or drop the leading zeros", text))
+	}
+
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(trimmed, 0, 64)
+		if err == nil {
+			if v.OverflowUint(u) {
+				return overflowError(path, text, v.Type())
+			}
+			v.SetUint(u)
+			return nil
+		}
+		if errors.Is(err, strconv.ErrRange) || strings.HasPrefix(trimmed, "-") {
+			return overflowError(path, text, v.Type())
+		}
+	default:
+		i, err := strconv.ParseInt(trimmed, 0, 64)
+		if err == nil {
+			return decodeInteger(i, text, v, path)
+		}
+		if errors.Is(err, strconv.ErrRange) && v.Kind() != reflect.Float32 && v.Kind() != reflect.Float64 {
+			return overflowError(path, text, v.Type())
+		}
+	}
+
+	// Fall back to float syntax (e.g., 1_000.5 or 1e3), which must be integral for integer types
+	f, err := strconv.ParseFloat(trimmed, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return overflowError(path, text, v.Type())
+		}
+		return decodeError(path, fmt.Errorf("invalid number %q", text))
+	}
+	return decodeFloat(f, text, v, path)
+}
+
+// hasLeadingZero reports whether text is a decimal integer with leading zeros (e.g., 0755),
+// which reads as octal in some languages and as decimal in others.
+func hasLeadingZero(text string) bool {
+	digits := strings.TrimLeft(text, "+-")
+	if len(digits) < 2 || digits[0] != '0' {
+		return false
+	}
+	for _, r := range digits[1:] {
+		if (r < '0' || r > '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
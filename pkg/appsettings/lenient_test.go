@@ -0,0 +1,145 @@
+package appsettings
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeLenientScalar(t *testing.T) {
+	tests := []struct {
+		text     string
+		target   interface{}
+		expected interface{}
+	}{
+		{"yes", new(bool), true},
+		{"ON", new(bool), true},
+		{" off ", new(bool), false},
+		{"n", new(bool), false},
+		{"1", new(bool), true},
+		{"1_000_000", new(int), 1000000},
+		{"0x1F", new(int), 31},
+		{"0o755", new(uint32), uint32(493)},
+		{"0b101", new(int8), int8(5)},
+		{"-0x10", new(int64), int64(-16)},
+		{"0", new(int), 0},
+		{"1e3", new(int), 1000},
+		{"1_000.5", new(float64), 1000.5},
+		{"0xFF", new(float32), float32(255)},
+		{"plain", new(string), "plain"},
+	}
+
+	for _, test := range tests {
+		v := reflect.ValueOf(test.target).Elem()
+		if err := decodeLenientScalar(test.text, v, "key"); err != nil {
+			t.Errorf("decodeLenientScalar(%q) returned error: %v", test.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(v.Interface(), test.expected) {
+			t.Errorf("decodeLenientScalar(%q) = %v, expected %v", test.text, v.Interface(), test.expected)
+		}
+	}
+}
+
+func TestDecodeLenientScalar_Errors(t *testing.T) {
+	tests := []struct {
+		text     string
+		target   interface{}
+		expected string
+	}{
+		{"enabled", new(bool), `key: invalid boolean "enabled"`},
+		{"0755", new(int), `key: ambiguous number "0755"`},
+		{"-007", new(float64), `key: ambiguous number "-007"`},
+		{"0x1G", new(int), `key: invalid number "0x1G"`},
+		{"1.5", new(int), "key: cannot decode string into int"},
+		{"0x100", new(uint8), "key: number 0x100 overflows uint8"},
+		{"-1", new(uint), "key: number -1 overflows uint"},
+		{"0x8000000000000000", new(int64), "key: number 0x8000000000000000 overflows int64"},
+	}
+
+	for _, test := range tests {
+		err := decodeLenientScalar(test.text, reflect.ValueOf(test.target).Elem(), "key")
+		if err == nil || !strings.HasPrefix(err.Error(), test.expected) {
+			t.Errorf("decodeLenientScalar(%q) = %v, expected error starting with %q", test.text, err, test.expected)
+		}
+	}
+}
+
+func TestIsLenientType(t *testing.T) {
+	tests := map[reflect.Type]bool{
+		reflect.TypeFor[bool]():          true,
+		reflect.TypeFor[uint16]():        true,
+		reflect.TypeFor[float64]():       true,
+		reflect.TypeFor[string]():        false,
+		reflect.TypeFor[time.Duration](): false,
+		reflect.TypeFor[ByteSize]():      false,
+	}
+
+	for typ, expected := range tests {
+		if result := isLenientType(typ); result != expected {
+			t.Errorf("isLenientType(%s) = %v, expected %v", typ, result, expected)
+		}
+	}
+}
+
+type LenientConfig struct {
+	Enabled  bool          `json:"enabled"`
+	Verbose  bool          `json:"verbose"`
+	Limit    int           `json:"limit"`
+	Mask     uint32        `json:"mask"`
+	Mode     uint32        `json:"mode"`
+	Port     int           `json:"port"`
+	Ratio    float64       `json:"ratio"`
+	Timeout  time.Duration `json:"timeout"`
+	Optional Optional[int] `json:"optional"`
+}
+
+func TestLoad_LenientScalars(t *testing.T) {
+	appSettings := New[LenientConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithLenientScalars().
+		WithEnvVars([]string{"ENABLED=yes", "LIMIT=1_000_000", "MASK=0x1F", "PORT=1", "TIMEOUT=30s", "OPTIONAL=0"}).
+		WithArgs([]string{"program", "--verbose", "off", "--mode", "0o755", "--set", "ratio=1_000.5"})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := LenientConfig{
+		Enabled:  true,
+		Verbose:  false,
+		Limit:    1000000,
+		Mask:     31,
+		Mode:     493,
+		Port:     1,
+		Ratio:    1000.5,
+		Timeout:  30 * time.Second,
+		Optional: Some(0),
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, *result)
+	}
+}
+
+func TestLoad_LenientScalarsAmbiguous(t *testing.T) {
+	_, err := New[LenientConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithLenientScalars().
+		WithEnvVars([]string{"MODE=0755"}).
+		Load()
+	if err == nil || !strings.Contains(err.Error(), `mode: ambiguous number "0755"`) {
+		t.Errorf("Expected ambiguous number error, got %v", err)
+	}
+}
+
+func TestLoad_LenientScalarsDisabled(t *testing.T) {
+	_, err := New[LenientConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithEnvVars([]string{"ENABLED=yes"}).
+		Load()
+	if err == nil || !strings.Contains(err.Error(), "enabled: cannot decode string into bool") {
+		t.Errorf("Expected type error without lenient scalars, got %v", err)
+	}
+}
@@ -2,76 +2,156 @@
 package appsettings
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 )
 
+const (
+	// envTagName is the struct tag controlling how environment variables are applied to a field.
+	envTagName = "env"
+	// envTagJSON marks a field whose environment variable value is parsed as JSON.
+	envTagJSON = "json"
+	// setArgName is the name of the repeatable command line argument for generic path overrides.
+	setArgName = "set"
+
+	// envVarsSource and argsSource name the environment variable and command line argument layers in warnings.
+	envVarsSource = "environment variables"
+	argsSource    = "command line arguments"
+)
+
 // AppSettings is a generic configuration loader that supports layered sources:
 // command line arguments, environment variables, environment-specific config files, and base config files.
 type AppSettings[T any] struct {
-	withArgs            []string
-	withEnvVars         []string
-	withEnvironment     *string
-	withConfigDirectory *string
+	withArgs                []string
+	withEnvVars             []string
+	withEnvironment         *string
+	withConfigDirectory     *string
+	withConfigEnvVar        *string
+	withLogger              *slog.Logger
+	withUnions              []unionDefinition
+	withLenientScalars      bool
+	withXMLAttributePrefix  string
+	withDotEnv              bool
+	withSources             []prioritizedSource
+	withLayerPriorities     map[Layer]int
+	withoutLayers           map[Layer]bool
+	withFiles               []configFile
+	withRequiredConfigFiles bool
 }
 
 // New creates a new AppSettings instance for the given config type.
 func New[T any]() *AppSettings[T] {
 	return &AppSettings[T]{
-		withArgs:            nil,
-		withEnvVars:         nil,
-		withEnvironment:     nil,
-		withConfigDirectory: nil,
+		withArgs:                nil,
+		withEnvVars:             nil,
+		withEnvironment:         nil,
+		withConfigDirectory:     nil,
+		withConfigEnvVar:        nil,
+		withLogger:              nil,
+		withUnions:              nil,
+		withLenientScalars:      false,
+		withXMLAttributePrefix:  "",
+		withDotEnv:              false,
+		withSources:             nil,
+		withLayerPriorities:     nil,
+		withoutLayers:           nil,
+		withFiles:               nil,
+		withRequiredConfigFiles: false,
 	}
 }
 
-// Load loads the configuration in the following priority order:
-// Args > EnvVars > ConfigFile.env.json > ConfigFile.json.
+// Load loads the configuration in the following priority order by default:
+// Args > EnvVars > ConfigEnvVar > .env.env > .env > WithFile files > ConfigFile.env.{json,yaml,...} > ConfigFile.{json,yaml,...}.
+// Sources added with WithSource are applied between these layers by priority, and the priorities
+// of the layers can be changed with WithLayerPriority.
 // It returns a pointer to the populated config struct of type T.
 func (a *AppSettings[T]) Load() (*T, error) {
-	configMap := make(map[string]interface{})
+	return a.LoadContext(context.Background())
+}
 
-	// Get working directory or config directory
-	configDir, err := a.getConfigDirectory()
+// LoadContext loads the configuration like Load, passing ctx to the sources added with WithSource so that
+// they can be cancelled or given a timeout. Load stops with the error of ctx once it is done.
+func (a *AppSettings[T]) LoadContext(ctx context.Context) (*T, error) {
+	configMap, err := a.loadConfigMap(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get config directory: %w", err)
+		return nil, err
 	}
 
-	// Load base config file
-	baseConfigPath := filepath.Join(*configDir, "config.json")
-	if err := a.loadConfigFile(baseConfigPath, configMap); err != nil {
-		return nil, fmt.Errorf("failed to load base config: %w", err)
+	// Unmarshal map into T and return
+	result, err := a.unmarshalToType(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	// Load environment-specific config file
-	if a.withEnvironment != nil {
-		envConfigPath := filepath.Join(*configDir, fmt.Sprintf("config.%s.json", *a.withEnvironment))
-		if err := a.loadConfigFile(envConfigPath, configMap); err != nil {
-			return nil, fmt.Errorf("failed to load env config: %w", err)
-		}
+	return result, nil
+}
+
+// LoadInto loads the configuration in the same priority order as Load and overlays it onto dst,
+// which may already hold defaults set in code or a previously loaded config. Only fields whose keys
+// are present in at least one source are overwritten; nested structs and maps are merged, and slices take
+// the length of the source array, keeping existing elements that are not set (e.g., by UPSTREAMS__1__PORT).
+// If an error is returned, dst may have been partially updated.
+func (a *AppSettings[T]) LoadInto(dst *T) error {
+	return a.LoadIntoContext(context.Background(), dst)
+}
+
+// LoadIntoContext overlays the configuration onto dst like LoadInto, passing ctx to the sources like LoadContext.
+func (a *AppSettings[T]) LoadIntoContext(ctx context.Context, dst *T) error {
+	if dst == nil {
+		return errors.New("destination must not be nil")
 	}
 
-	// Overlay environment variables
-	if err := a.loadEnvVars(configMap); err != nil {
-		return nil, fmt.Errorf("failed to load env vars: %w", err)
+	configMap, err := a.loadConfigMap(ctx)
+	if err != nil {
+		return err
 	}
 
-	// Overlay command line arguments
-	if err := a.loadArgs(configMap); err != nil {
-		return nil, fmt.Errorf("failed to load args: %w", err)
+	if err := a.unmarshalInto(configMap, dst); err != nil {
+		return fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	// Unmarshal map into T and return
-	result, err := a.unmarshalToType(configMap)
+	return nil
+}
+
+// loadConfigMap loads all sources and merges them into a single config map by priority.
+func (a *AppSettings[T]) loadConfigMap(ctx context.Context) (map[string]interface{}, error) {
+	configMap := make(map[string]interface{})
+
+	// Validate registered type information
+	if _, err := a.schema(); err != nil {
+		return nil, fmt.Errorf("invalid config schema: %w", err)
+	}
+
+	// Get working directory or config directory
+	configDir, err := a.getConfigDirectory()
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
 	}
 
-	return result, nil
+	layers, err := a.layers(*configDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid layers: %w", err)
+	}
+
+	// Overlay the layers in priority order
+	for _, layer := range layers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := layer.load(ctx, configMap); err != nil {
+			return nil, err
+		}
+	}
+
+	return configMap, nil
 }
 
 // getWD returns the directory of the running executable.
@@ -108,6 +188,140 @@ func (a *AppSettings[T]) WithConfigDirectory(configDirectory string) *AppSetting
 	return a
 }
 
+// FileRequirement defines whether a missing config file makes Load fail.
+type FileRequirement int
+
+const (
+	// FileOptional config files are ignored if missing.
+	FileOptional FileRequirement = iota
+	// FileRequired config files make Load fail if missing.
+	FileRequired
+)
+
+// configFile is a config file added with WithFile.
+type configFile struct {
+	path        string
+	requirement FileRequirement
+}
+
+// WithFile adds a config file (e.g., "secrets.yaml" or "/etc/app/overrides.json") in the format given by its
+// extension. Files are applied after the environment-specific config files in the order they were added.
+// Relative paths are resolved against the config directory. A missing FileRequired file makes Load fail.
+func (a *AppSettings[T]) WithFile(path string, requirement FileRequirement) *AppSettings[T] {
+	a.withFiles = append(a.withFiles, configFile{path: path, requirement: requirement})
+	return a
+}
+
+// WithRequiredConfigFiles makes Load fail if no base config file exists in the config directory or, with an
+// environment set, no config file for the environment exists (e.g., because of a typo in the environment name).
+func (a *AppSettings[T]) WithRequiredConfigFiles() *AppSettings[T] {
+	a.withRequiredConfigFiles = true
+	return a
+}
+
+// WithConfigEnvVar sets the name of an environment variable (e.g., "APP_CONFIG_JSON") holding a full JSON config document.
+// The document is applied between the environment-specific config file and individual environment variables.
+func (a *AppSettings[T]) WithConfigEnvVar(name string) *AppSettings[T] {
+	a.withConfigEnvVar = &name
+	return a
+}
+
+// WithLogger sets the logger used for warnings (e.g., config keys differing only in case).
+// If not set, slog.Default() is used.
+func (a *AppSettings[T]) WithLogger(logger *slog.Logger) *AppSettings[T] {
+	a.withLogger = logger
+	return a
+}
+
+// logger returns the configured logger, falling back to slog.Default() if not set.
+func (a *AppSettings[T]) logger() *slog.Logger {
+	if a.withLogger != nil {
+		return a.withLogger
+	}
+	return slog.Default()
+}
+
+// WithUnion registers a discriminated union for a config section declared as an interface type.
+// iface is a nil pointer to the interface (e.g., (*Storage)(nil)), discriminator is the key selecting
+// the variant (e.g., "type") and variants maps each discriminator value to a value of its concrete type
+// (e.g., {"s3": S3Storage{}, "local": LocalStorage{}}). Invalid definitions make Load fail.
+func (a *AppSettings[T]) WithUnion(iface any, discriminator string, variants map[string]any) *AppSettings[T] {
+	a.withUnions = append(a.withUnions, unionDefinition{iface: iface, discriminator: discriminator, variants: variants})
+	return a
+}
+
+// WithLenientScalars enables a lenient grammar for string values of bool and numeric fields from any source:
+// booleans may be written as yes/no, on/off, y/n or 1/0, and numbers may contain underscores and 0x, 0o or 0b
+// base prefixes (e.g., 1_000_000, 0x1F or 0o755). Ambiguous values such as 0755 make Load fail.
+func (a *AppSettings[T]) WithLenientScalars() *AppSettings[T] {
+	a.withLenientScalars = true
+	return a
+}
+
+// WithXMLAttributePrefix sets the prefix of the keys XML attributes map to (e.g., "@" maps <database host="...">
+// to the key "@host"). By default, attributes map to keys named like child elements.
+func (a *AppSettings[T]) WithXMLAttributePrefix(prefix string) *AppSettings[T] {
+	a.withXMLAttributePrefix = prefix
+	return a
+}
+
+// WithDotEnv enables loading environment variables from the .env and .env.<environment> files in the config directory.
+// They are applied like environment variables between the environment-specific config file and the config env var,
+// so real environment variables take precedence. Missing files are ignored.
+func (a *AppSettings[T]) WithDotEnv() *AppSettings[T] {
+	a.withDotEnv = true
+	return a
+}
+
+// schema returns the schema holding the type information registered on this instance.
+func (a *AppSettings[T]) schema() (*schema, error) {
+	s, err := newSchema(reflect.TypeFor[T](), a.withUnions)
+	if err != nil {
+		return nil, err
+	}
+	s.lenientScalars = a.withLenientScalars
+	return s, nil
+}
+
+// canonicalize renames the keys of a layer to the config keys of the fields of T and logs a warning
+// for each pair of keys within the layer that differ only in case. Union variants are selected by the
+// discriminators in the layer itself or, if absent, in configMap. Relative paths are resolved against dir.
+func (a *AppSettings[T]) canonicalize(
+	s *schema, layer interface{}, t reflect.Type, path []string, configMap map[string]interface{}, source, dir string,
+) interface{} {
+	return a.canonicalizeWith(&canonicalizer{schema: s, merged: configMap, dir: dir}, layer, t, path, source)
+}
+
+// canonicalizeWith canonicalizes a layer like canonicalize using the configured canonicalizer c.
+func (a *AppSettings[T]) canonicalizeWith(c *canonicalizer, layer interface{}, t reflect.Type, path []string, source string) interface{} {
+	result := c.value(layer, t, path)
+	for _, warning := range c.warnings {
+		a.logger().Warn("duplicate config key: "+warning, "source", source)
+	}
+	return result
+}
+
+// canonicalPath resolves a key path against the fields of T and logs a warning if the same path of a field
+// was already set by a differently named key of the same layer, tracked in seen. It returns the
+// canonical path, the type each key is looked up in, the type reached and the last struct field on the path.
+func (a *AppSettings[T]) canonicalPath(
+	s *schema, path []string, configMap map[string]interface{}, name string, seen map[string]string, source string,
+) ([]string, []reflect.Type, reflect.Type, *reflect.StructField) {
+	canonical, containers, leaf, field := s.resolvePath(reflect.TypeFor[T](), path, configMap)
+	if leaf == nil {
+		return canonical, containers, leaf, field // Keys outside of T (e.g., http_proxy and HTTP_PROXY) are ignored
+	}
+
+	joined := strings.Join(canonical, argPathSeparator)
+	if origin, duplicate := seen[joined]; duplicate && origin != name {
+		a.logger().Warn(fmt.Sprintf("duplicate config key: keys %q and %q of %q differ only in case, using %q",
+			origin, name, joined, name), "source", source)
+	}
+	seen[joined] = name
+
+	return canonical, containers, leaf, field
+}
+
 // getConfigDirectory returns the config directory, falling back to the executable directory if not set.
 func (a *AppSettings[T]) getConfigDirectory() (*string, error) {
 	if a.withConfigDirectory != nil {
@@ -116,8 +330,8 @@ func (a *AppSettings[T]) getConfigDirectory() (*string, error) {
 	return a.getWD()
 }
 
-// loadConfigFile loads a JSON config file and merges its values into configMap.
-// If the file does not exist, it is silently ignored.
+// loadConfigFile loads a config file in the format given by its extension (e.g., JSON or YAML)
+// and deep merges its values into configMap. If the file does not exist, it is silently ignored.
 func (a *AppSettings[T]) loadConfigFile(filePath string, configMap map[string]interface{}) error {
 	//nolint:gosec // filePath is constructed from trusted config directory and filename
 	data, err := os.ReadFile(filePath)
@@ -128,59 +342,277 @@ func (a *AppSettings[T]) loadConfigFile(filePath string, configMap map[string]in
 		return err
 	}
 
-	var fileConfig map[string]interface{}
-	if err := json.Unmarshal(data, &fileConfig); err != nil {
+	format, ok := lookupFormat(filePath)
+	if !ok {
+		return fmt.Errorf("unsupported config file format %q", filepath.Ext(filePath))
+	}
+
+	decode := format.decode
+	if format.xmlAttributes && a.withXMLAttributePrefix != "" {
+		decode = xmlDecoder{attributePrefix: a.withXMLAttributePrefix}.decode
+	}
+
+	fileConfig, err := decode(data)
+	if err != nil {
+		return err
+	}
+
+	s, err := a.schema()
+	if err != nil {
+		return err
+	}
+
+	// Relative paths in the file are resolved against its directory
+	dir, err := filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		return err
+	}
+
+	// Deep merge into configMap
+	c := &canonicalizer{
+		schema: s, merged: configMap, dir: dir, singleElements: format.singleElements, untypedScalars: format.untypedScalars,
+	}
+	fileConfig, _ = a.canonicalizeWith(c, fileConfig, reflect.TypeFor[T](), nil, filePath).(map[string]interface{})
+	mergeMaps(configMap, fileConfig)
+
+	return nil
+}
+
+// loadFiles overlays the config files added with WithFile into configMap, resolving relative paths against dir.
+func (a *AppSettings[T]) loadFiles(dir string, configMap map[string]interface{}) error {
+	for _, file := range a.withFiles {
+		filePath := file.path
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(dir, filePath)
+		}
+
+		if file.requirement == FileRequired {
+			if err := requireConfigFile([]string{filePath}); err != nil {
+				return fmt.Errorf("failed to load file %q: %w", file.path, err)
+			}
+		}
+		if err := a.loadConfigFile(filePath, configMap); err != nil {
+			return fmt.Errorf("failed to load file %q: %w", file.path, err)
+		}
+	}
+	return nil
+}
+
+// requireConfigFile returns an error if none of the config files at paths exists.
+func requireConfigFile(paths []string) error {
+	names := make([]string, 0, len(paths))
+	for _, filePath := range paths {
+		if _, err := os.Stat(filePath); err == nil || !os.IsNotExist(err) {
+			return nil // Other errors are reported when reading the file
+		}
+		names = append(names, filepath.Base(filePath))
+	}
+	return fmt.Errorf("required config file not found in %q, expected %s", filepath.Dir(paths[0]), strings.Join(names, ", "))
+}
+
+// loadConfigEnvVar overlays the JSON document held by the whole-config environment variable into configMap.
+// If the variable is not configured or not set, it is silently ignored.
+func (a *AppSettings[T]) loadConfigEnvVar(configMap map[string]interface{}) error {
+	return a.applyConfigEnvVar(configMap, a.withEnvVars)
+}
+
+// applyConfigEnvVar overlays the JSON document held by the whole-config environment variable in envVars into configMap.
+func (a *AppSettings[T]) applyConfigEnvVar(configMap map[string]interface{}, envVars []string) error {
+	if a.withConfigEnvVar == nil {
+		return nil
+	}
+
+	s, err := a.schema()
+	if err != nil {
 		return err
 	}
 
-	// Merge into configMap
-	for key, value := range fileConfig {
-		configMap[key] = value
+	for _, envVar := range envVars {
+		name, value, ok := strings.Cut(envVar, "=")
+		if !ok || name != *a.withConfigEnvVar {
+			continue
+		}
+
+		var envConfig map[string]interface{}
+		if err := unmarshalJSON([]byte(value), &envConfig); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		// Deep merge into configMap
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		envConfig, _ = a.canonicalize(s, envConfig, reflect.TypeFor[T](), nil, configMap, name, cwd).(map[string]interface{})
+		mergeMaps(configMap, envConfig)
 	}
 
 	return nil
 }
 
+// loadDotEnv overlays the variables of the .env and .env.<environment> files in dir into configMap
+// like environment variables, including the whole-config environment variable. Missing files are ignored.
+func (a *AppSettings[T]) loadDotEnv(dir string, configMap map[string]interface{}) error {
+	if !a.withDotEnv {
+		return nil
+	}
+
+	paths := []string{filepath.Join(dir, dotEnvFileName)}
+	if a.withEnvironment != nil {
+		paths = append(paths, filepath.Join(dir, dotEnvFileName+"."+*a.withEnvironment))
+	}
+
+	var envVars []string
+	for _, filePath := range paths {
+		//nolint:gosec // filePath is constructed from trusted config directory and filename
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // Dotenv files are optional
+			}
+			return err
+		}
+
+		// Variables are expanded from earlier assignments first, then from real environment variables
+		fileVars, err := decodeDotEnv(data, func(name string) (string, bool) {
+			if value, ok := lookupEnvVar(envVars, name); ok {
+				return value, true
+			}
+			return lookupEnvVar(a.withEnvVars, name)
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", filePath, err)
+		}
+		envVars = append(envVars, fileVars...)
+	}
+
+	if err := a.applyConfigEnvVar(configMap, envVars); err != nil {
+		return err
+	}
+	return a.applyEnvVars(configMap, envVars, dotEnvFileName)
+}
+
+// lookupEnvVar returns the value of the last entry named name in the NAME=value entries of envVars.
+func lookupEnvVar(envVars []string, name string) (string, bool) {
+	for i := len(envVars) - 1; i >= 0; i-- {
+		if key, value, ok := strings.Cut(envVars[i], "="); ok && key == name {
+			return value, true
+		}
+	}
+	return "", false
+}
+
 // loadEnvVars overlays environment variables into configMap, converting values to appropriate types.
+// Nested keys and array elements are addressed with double underscores (e.g., UPSTREAMS__0__HOST).
+// Values of fields tagged with `env:"json"` are parsed as JSON objects or arrays.
 func (a *AppSettings[T]) loadEnvVars(configMap map[string]interface{}) error {
-	if a.withEnvVars == nil {
+	return a.applyEnvVars(configMap, a.withEnvVars, envVarsSource)
+}
+
+// applyEnvVars overlays the NAME=value entries of envVars into configMap like loadEnvVars,
+// naming source in warnings.
+func (a *AppSettings[T]) applyEnvVars(configMap map[string]interface{}, envVars []string, source string) error {
+	if envVars == nil {
 		return nil
 	}
 
-	for _, envVar := range a.withEnvVars {
+	s, err := a.schema()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]string)
+	for _, envVar := range envVars {
 		parts := strings.SplitN(envVar, "=", 2)
 		if len(parts) != 2 {
 			continue
 		}
 
+		// Skip the whole-config environment variable, it is loaded separately
+		if a.withConfigEnvVar != nil && parts[0] == *a.withConfigEnvVar {
+			continue
+		}
+
 		key := strings.ToLower(parts[0])
 		value := parts[1]
+		path, containers, leaf, field := a.canonicalPath(s, s.prefixes.envPath(key), configMap, parts[0], seen, source)
+
+		// Parse value as JSON if the target field opted in
+		if jsonField, ok := s.lookupField(reflect.TypeFor[T](), path, configMap); ok && jsonField.Tag.Get(envTagName) == envTagJSON {
+			var jsonValue interface{}
+			if err := unmarshalJSON([]byte(value), &jsonValue); err != nil {
+				return fmt.Errorf("%s: %w", parts[0], err)
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			jsonValue = a.canonicalize(s, jsonValue, leaf, path, configMap, parts[0], cwd)
+			if isPathField(field, leaf) {
+				jsonValue = resolvePaths(jsonValue, cwd)
+			}
+			if err := setTypedPath(configMap, path, containers, jsonValue); err != nil {
+				return fmt.Errorf("%s: %w", parts[0], err)
+			}
+			continue
+		}
 
 		// Convert value to appropriate type if possible
-		configMap[key] = a.parseValue(value)
+		parsed, err := a.sourceValue(field, leaf, value)
+		if err != nil {
+			return err
+		}
+		if err := setTypedPath(configMap, path, containers, parsed); err != nil {
+			return fmt.Errorf("%s: %w", parts[0], err)
+		}
 	}
 
 	return nil
 }
 
 // loadArgs overlays command line arguments into configMap, converting values to appropriate types.
-// Supports --key value and --flag formats.
+// Supports --key value, --flag and repeatable --set key.path=value formats. Nested keys and
+// array elements are addressed with dots (e.g., --upstreams.1.port 81).
 func (a *AppSettings[T]) loadArgs(configMap map[string]interface{}) error {
 	if a.withArgs == nil {
 		return nil
 	}
 
+	s, err := a.schema()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]string)
 	for i, arg := range a.withArgs {
 		if strings.HasPrefix(arg, "--") {
-			key := strings.TrimPrefix(arg, "--")
-			key = strings.ToLower(key)
+			name := strings.TrimPrefix(arg, "--")
+			key := strings.ToLower(name)
+
+			// Generic override of any path in the config tree
+			if key == setArgName {
+				if i+1 >= len(a.withArgs) {
+					return fmt.Errorf("missing value for --%s, expected key.path=value", setArgName)
+				}
+				if err := a.loadSetArg(s, configMap, a.withArgs[i+1], seen); err != nil {
+					return err
+				}
+				continue
+			}
+
+			path, containers, leaf, field := a.canonicalPath(s, s.prefixes.argPath(key), configMap, name, seen, argsSource)
 
 			// Check if there's a value after this argument
+			var value interface{} = true // Flag without value
 			if i+1 < len(a.withArgs) && !strings.HasPrefix(a.withArgs[i+1], "--") {
-				value := a.withArgs[i+1]
-				configMap[key] = a.parseValue(value)
-			} else {
-				configMap[key] = true // Flag without value
+				value, err = a.sourceValue(field, leaf, a.withArgs[i+1])
+				if err != nil {
+					return err
+				}
+			}
+			if err := setTypedPath(configMap, path, containers, value); err != nil {
+				return fmt.Errorf("--%s: %w", name, err)
 			}
 		}
 	}
@@ -188,17 +620,120 @@ func (a *AppSettings[T]) loadArgs(configMap map[string]interface{}) error {
 	return nil
 }
 
-// parseValue attempts to convert a string to bool, int, float, or returns the original string.
-func (a *AppSettings[T]) parseValue(value string) interface{} {
+// loadSetArg overlays a single key.path=value assignment of a --set argument into configMap.
+func (a *AppSettings[T]) loadSetArg(
+	s *schema, configMap map[string]interface{}, assignment string, seen map[string]string,
+) error {
+	name, value, ok := strings.Cut(assignment, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("invalid --%s value %q, expected key.path=value", setArgName, assignment)
+	}
+
+	key := strings.ToLower(name)
+	path, containers, leaf, field := a.canonicalPath(s, s.prefixes.argPath(key), configMap, name, seen, argsSource)
+	parsed, err := a.sourceValue(field, leaf, value)
+	if err != nil {
+		return err
+	}
+	if err := setTypedPath(configMap, path, containers, parsed); err != nil {
+		return fmt.Errorf("--%s %s: %w", setArgName, name, err)
+	}
+	return nil
+}
+
+// sourceValue converts a value of an environment variable or command line argument for the field and type
+// reached by its key. Relative paths are resolved against the working directory, other values are converted
+// for the type with typedTextValue.
+func (a *AppSettings[T]) sourceValue(field *reflect.StructField, leaf reflect.Type, value string) (interface{}, error) {
+	if isPathField(field, leaf) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		return absolutePath(cwd, value), nil
+	}
+	return typedTextValue(value, leaf, a.withLenientScalars), nil
+}
+
+// parseTextValue attempts to convert a value of an untyped source whose type is unknown to bool, int, float,
+// or returns the original string. Integers exceeding the int range are kept as json.Number to preserve their precision.
+func parseTextValue(value string) interface{} {
 	// Try to parse as bool
 	if boolVal, err := strconv.ParseBool(value); err == nil {
 		return boolVal
 	}
 
+	// Try to parse as int or float, or return as string
+	return parseNumberText(value)
+}
+
+// typedTextValue converts a value of an untyped source (e.g., an environment variable or an INI file) for a field
+// of type t. Values of bool and numeric fields are parsed as such, and values of string fields and of types decoded
+// from strings (e.g., with a registered decoder or the lenient scalar grammar if lenient is set) are kept as strings.
+// Values of other or unknown types are converted with parseTextValue.
+func typedTextValue(value string, t reflect.Type, lenient bool) interface{} {
+	if t == nil {
+		return parseTextValue(value)
+	}
+
+	base := elemType(t)
+	if hasRegisteredDecoder(t) || reflect.PointerTo(base).Implements(textUnmarshalerType) {
+		return value
+	}
+	if reflect.PointerTo(base).Implements(jsonUnmarshalerType) {
+		return parseTextValue(value)
+	}
+	if lenient && isLenientType(base) {
+		return value
+	}
+
+	switch {
+	case base == durationType:
+		return parseNumberText(value)
+	case base.Kind() == reflect.String:
+		return value
+	case base.Kind() == reflect.Bool:
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+		return value
+	case isLenientKind(base.Kind()):
+		return parseNumberText(value)
+	default:
+		return parseTextValue(value)
+	}
+}
+
+// hasRegisteredDecoder reports whether a decoder is registered for t or for any of the pointer and wrapper
+// types on the way to the type holding the config value (e.g., *url.URL for a Secret[*url.URL] field).
+func hasRegisteredDecoder(t reflect.Type) bool {
+	for {
+		if _, found := lookupDecoder(t); found {
+			return true
+		}
+
+		switch {
+		case t.Kind() == reflect.Pointer:
+			t = t.Elem()
+		case t.Implements(valueWrapperType):
+			t = reflect.Zero(t).Interface().(valueWrapper).wrappedType()
+		default:
+			return false
+		}
+	}
+}
+
+// parseNumberText converts a string to int or float, or returns the original string.
+// Integers exceeding the int range are kept as json.Number to preserve their precision.
+func parseNumberText(value string) interface{} {
 	// Try to parse as int
-	if intVal, err := strconv.Atoi(value); err == nil {
+	intVal, err := strconv.Atoi(value)
+	if err == nil {
 		return intVal
 	}
+	if errors.Is(err, strconv.ErrRange) {
+		return json.Number(value)
+	}
 
 	// Try to parse as float
 	if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
@@ -209,17 +744,28 @@ func (a *AppSettings[T]) parseValue(value string) interface{} {
 	return value
 }
 
-// unmarshalToType marshals configMap to JSON and unmarshals it into type T.
+// unmarshalToType decodes configMap into a new value of type T.
+// Field names are matched like in encoding/json; string values are additionally decoded with
+// registered decoders (see RegisterDecoder) and encoding.TextUnmarshaler implementations.
 func (a *AppSettings[T]) unmarshalToType(configMap map[string]interface{}) (*T, error) {
-	jsonData, err := json.Marshal(configMap)
-	if err != nil {
-		return nil, err
-	}
-
 	var result T
-	if err := json.Unmarshal(jsonData, &result); err != nil {
+	if err := a.unmarshalInto(configMap, &result); err != nil {
 		return nil, err
 	}
 
 	return &result, nil
 }
+
+// unmarshalInto decodes configMap onto dst, leaving fields without a key in configMap unchanged.
+func (a *AppSettings[T]) unmarshalInto(configMap map[string]interface{}, dst *T) error {
+	if err := checkRawValue(configMap, ""); err != nil {
+		return err
+	}
+
+	s, err := a.schema()
+	if err != nil {
+		return err
+	}
+
+	return s.decodeValue(configMap, reflect.ValueOf(dst).Elem(), "")
+}
@@ -1,10 +1,14 @@
 package appsettings
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -38,6 +42,18 @@ func TestNew(t *testing.T) {
 	if appSettings.withConfigDirectory != nil {
 		t.Error("Expected withConfigDirectory to be nil")
 	}
+
+	if appSettings.withConfigEnvVar != nil {
+		t.Error("Expected withConfigEnvVar to be nil")
+	}
+
+	if appSettings.withLogger != nil {
+		t.Error("Expected withLogger to be nil")
+	}
+
+	if appSettings.withUnions != nil {
+		t.Error("Expected withUnions to be nil")
+	}
 }
 
 func TestWithArgs(t *testing.T) {
@@ -100,6 +116,40 @@ func TestWithConfigDirectory(t *testing.T) {
 	}
 }
 
+func TestWithConfigEnvVar(t *testing.T) {
+	appSettings := New[TestConfig]()
+	name := "APP_CONFIG_JSON"
+
+	result := appSettings.WithConfigEnvVar(name)
+
+	if result != appSettings {
+		t.Error("WithConfigEnvVar should return the same instance for chaining")
+	}
+
+	if appSettings.withConfigEnvVar == nil || *appSettings.withConfigEnvVar != name {
+		t.Errorf("Expected config env var %s, got %v", name, appSettings.withConfigEnvVar)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	appSettings := New[TestConfig]()
+	logger := slog.New(slog.DiscardHandler)
+
+	result := appSettings.WithLogger(logger)
+
+	if result != appSettings {
+		t.Error("WithLogger should return the same instance for chaining")
+	}
+
+	if appSettings.withLogger != logger || appSettings.logger() != logger {
+		t.Errorf("Expected logger %v, got %v", logger, appSettings.withLogger)
+	}
+
+	if New[TestConfig]().logger() != slog.Default() {
+		t.Error("Expected logger to fall back to slog.Default()")
+	}
+}
+
 func TestGetWD(t *testing.T) {
 	appSettings := New[TestConfig]()
 
@@ -152,7 +202,7 @@ func TestLoadConfigFile_FileExists(t *testing.T) {
 
 	testConfig := map[string]interface{}{
 		"databaseURL": "postgres://localhost/test",
-		"port":        float64(8080),
+		"port":        json.Number("8080"),
 		"debugMode":   true,
 	}
 
@@ -233,8 +283,8 @@ func TestLoadEnvVars(t *testing.T) {
 
 	expected := map[string]interface{}{
 		"port":        8080,
-		"databaseurl": "postgres://localhost/test",
-		"debugmode":   true,
+		"databaseURL": "postgres://localhost/test",
+		"debugMode":   true,
 		"timeout":     30.5,
 		"name":        "test-app",
 		"another":     "INVALID",
@@ -375,9 +425,7 @@ func TestLoadArgs_NoArgs(t *testing.T) {
 	}
 }
 
-func TestParseValue(t *testing.T) {
-	appSettings := New[TestConfig]()
-
+func TestParseTextValue(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected interface{}
@@ -396,12 +444,13 @@ func TestParseValue(t *testing.T) {
 		{"", ""},
 		{"123abc", "123abc"},
 		{"true123", "true123"},
+		{"18446744073709551615", json.Number("18446744073709551615")},
 	}
 
 	for _, test := range tests {
-		result := appSettings.parseValue(test.input)
+		result := parseTextValue(test.input)
 		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("parseValue(%q) = %v (%T), expected %v (%T)",
+			t.Errorf("parseTextValue(%q) = %v (%T), expected %v (%T)",
 				test.input, result, result, test.expected, test.expected)
 		}
 	}
@@ -744,3 +793,683 @@ func TestLoad_ComplexConfig(t *testing.T) {
 		t.Errorf("Expected 2 features, got %d", len(result.Features))
 	}
 }
+
+type Upstream struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type UpstreamConfig struct {
+	Upstreams []Upstream `json:"upstreams"`
+}
+
+func TestLoad_ArrayOfStructsOverrides(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfig := map[string]interface{}{
+		"upstreams": []map[string]interface{}{
+			{"host": "file-a", "port": 80},
+			{"host": "file-b", "port": 80},
+		},
+	}
+	baseData, err := json.Marshal(baseConfig)
+	if err != nil {
+		t.Fatalf("Failed to marshal base config: %v", err)
+	}
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	appSettings := New[UpstreamConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvVars([]string{
+			"UPSTREAMS__0__HOST=env-a",
+			"UPSTREAMS__2__HOST=env-c",
+		}).
+		WithArgs([]string{
+			"program",
+			"--upstreams.1.port", "81",
+			"--upstreams.2.port", "82",
+		})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := &UpstreamConfig{
+		Upstreams: []Upstream{
+			{Host: "env-a", Port: 80},
+			{Host: "file-b", Port: 81},
+			{Host: "env-c", Port: 82},
+		},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected config %+v, got %+v", expected, result)
+	}
+}
+
+func TestLoad_ArrayIndexOutOfRange(t *testing.T) {
+	tests := map[string]*AppSettings[UpstreamConfig]{
+		"UPSTREAMS__1000000000__HOST: ":         New[UpstreamConfig]().WithEnvVars([]string{"UPSTREAMS__1000000000__HOST=x"}),
+		"--upstreams.50000000.host: ":           New[UpstreamConfig]().WithArgs([]string{"--upstreams.50000000.host", "x"}),
+		"--set upstreams.50000000.host: ":       New[UpstreamConfig]().WithArgs([]string{"--set", "upstreams.50000000.host=x"}),
+		"array index 1000000000 out of range, ": New[UpstreamConfig]().WithEnvVars([]string{"UPSTREAMS__0__HOST=a", "UPSTREAMS__1000000000__HOST=x"}),
+	}
+
+	for expected, appSettings := range tests {
+		_, err := appSettings.WithConfigDirectory(t.TempDir()).Load()
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestLoad_NumericKeysOfMaps(t *testing.T) {
+	type Config struct {
+		Codes     map[int]string      `json:"codes"`
+		Upstreams map[string]Upstream `json:"upstreams"`
+	}
+
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "config.ini"), []byte("[codes]\n500 = error\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	result, err := New[Config]().
+		WithConfigDirectory(tempDir).
+		WithEnvVars([]string{"CODES__404=nf", "UPSTREAMS__12__PORT=81", "FOO__5000000=x"}).
+		WithArgs([]string{"--upstreams.7.host", "b", "--set", "bar.9000000=y"}).
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := Config{
+		Codes:     map[int]string{404: "nf", 500: "error"},
+		Upstreams: map[string]Upstream{"12": {Port: 81}, "7": {Host: "b"}},
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, *result)
+	}
+}
+
+func TestLoad_NestedEnvConfigMerge(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	baseData := []byte(`{"database": {"host": "localhost", "port": 5432, "username": "user"}}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	envConfigFile := filepath.Join(tempDir, "config.dev.json")
+	envData := []byte(`{"database": {"host": "dev-server"}}`)
+	if err := os.WriteFile(envConfigFile, envData, 0600); err != nil {
+		t.Fatalf("Failed to write env config: %v", err)
+	}
+
+	appSettings := New[ComplexConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvironment("dev").
+		WithEnvVars([]string{"DATABASE__PASSWORD=secret"})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.Database.Host != "dev-server" {
+		t.Errorf("Expected database host 'dev-server', got %s", result.Database.Host)
+	}
+
+	if result.Database.Port != 5432 {
+		t.Errorf("Expected database port 5432, got %d", result.Database.Port)
+	}
+
+	if result.Database.Username != "user" {
+		t.Errorf("Expected database username 'user', got %s", result.Database.Username)
+	}
+
+	if result.Database.Password != "secret" {
+		t.Errorf("Expected database password 'secret', got %s", result.Database.Password)
+	}
+}
+
+type JSONEnvConfig struct {
+	Upstreams []Upstream `env:"json" json:"upstreams"`
+	Primary   Upstream   `env:"json" json:"primary"`
+	Name      string     `json:"name"`
+}
+
+func TestLoadEnvVars_JSONValues(t *testing.T) {
+	appSettings := New[JSONEnvConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithEnvVars([]string{
+			`UPSTREAMS=[{"host": "a", "port": 80}, {"host": "b", "port": 81}]`,
+			`PRIMARY={"host": "p", "port": 443}`,
+			`NAME={"not": "parsed"}`,
+		})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := &JSONEnvConfig{
+		Upstreams: []Upstream{{Host: "a", Port: 80}, {Host: "b", Port: 81}},
+		Primary:   Upstream{Host: "p", Port: 443},
+		Name:      `{"not": "parsed"}`,
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected config %+v, got %+v", expected, result)
+	}
+}
+
+func TestLoadEnvVars_InvalidJSONValue(t *testing.T) {
+	appSettings := New[JSONEnvConfig]().
+		WithEnvVars([]string{"UPSTREAMS=[invalid"})
+
+	configMap := make(map[string]interface{})
+	err := appSettings.loadEnvVars(configMap)
+	if err == nil {
+		t.Error("loadEnvVars() should return error for invalid JSON value")
+	}
+}
+
+func TestLoad_ConfigEnvVar(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	baseData := []byte(`{"databaseURL": "postgres://localhost/base", "port": 8000, "name": "base-app"}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	envConfigFile := filepath.Join(tempDir, "config.dev.json")
+	envData := []byte(`{"port": 8001, "timeout": 10.0}`)
+	if err := os.WriteFile(envConfigFile, envData, 0600); err != nil {
+		t.Fatalf("Failed to write env config: %v", err)
+	}
+
+	appSettings := New[TestConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvironment("dev").
+		WithConfigEnvVar("APP_CONFIG_JSON").
+		WithEnvVars([]string{
+			`APP_CONFIG_JSON={"port": 9000, "timeout": 20.0, "debugMode": true}`,
+			"TIMEOUT=30.5",
+		})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := &TestConfig{
+		DatabaseURL: "postgres://localhost/base",
+		Port:        9000,
+		DebugMode:   true,
+		Timeout:     30.5,
+		Name:        "base-app",
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected config %+v, got %+v", expected, result)
+	}
+}
+
+func TestLoad_InvalidConfigEnvVar(t *testing.T) {
+	appSettings := New[TestConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithConfigEnvVar("APP_CONFIG_JSON").
+		WithEnvVars([]string{"APP_CONFIG_JSON={invalid}"})
+
+	_, err := appSettings.Load()
+	if err == nil {
+		t.Error("Load() should return error for invalid config env var")
+	}
+}
+
+func TestLoadArgs_Set(t *testing.T) {
+	appSettings := New[ComplexConfig]()
+	appSettings.WithArgs([]string{
+		"program",
+		"--set", "database.host=db.internal",
+		"--port", "9000",
+		"--set", "Database.Port=6543",
+		"--set", "features.1=beta",
+		"--set", "database.password=a=b",
+	})
+
+	configMap := make(map[string]interface{})
+	err := appSettings.loadArgs(configMap)
+	if err != nil {
+		t.Fatalf("loadArgs() returned error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"database": map[string]interface{}{
+			"host":     "db.internal",
+			"port":     6543,
+			"password": "a=b",
+		},
+		"port":     9000,
+		"features": []interface{}{nil, "beta"},
+	}
+
+	if !reflect.DeepEqual(configMap, expected) {
+		t.Errorf("Expected config %v, got %v", expected, configMap)
+	}
+}
+
+func TestLoadArgs_SetInvalid(t *testing.T) {
+	tests := [][]string{
+		{"program", "--set"},
+		{"program", "--set", "database.host"},
+		{"program", "--set", "=value"},
+	}
+
+	for _, args := range tests {
+		appSettings := New[ComplexConfig]().WithArgs(args)
+
+		configMap := make(map[string]interface{})
+		if err := appSettings.loadArgs(configMap); err == nil {
+			t.Errorf("loadArgs(%v) should return error", args)
+		}
+	}
+}
+
+func TestLoad_SetOverridesNestedConfig(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	baseData := []byte(`{"database": {"host": "localhost", "port": 5432}, "cache": {"ttl": 300}}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	appSettings := New[ComplexConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvVars([]string{"CACHE__TTL=600"}).
+		WithArgs([]string{"program", "--set", "cache.ttl=900", "--set", "database.port=6543"})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.Database.Host != "localhost" {
+		t.Errorf("Expected database host 'localhost', got %s", result.Database.Host)
+	}
+
+	if result.Database.Port != 6543 {
+		t.Errorf("Expected database port 6543, got %d", result.Database.Port)
+	}
+
+	if result.Cache.TTL != 900 {
+		t.Errorf("Expected cache ttl 900, got %d", result.Cache.TTL)
+	}
+}
+
+func TestLoad_CanonicalKeysFollowLayerPriority(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	baseData := []byte(`{"DatabaseUrl": "postgres://localhost/base", "port": 8000}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	envConfigFile := filepath.Join(tempDir, "config.dev.json")
+	envData := []byte(`{"databaseURL": "postgres://localhost/dev", "PORT": 8001}`)
+	if err := os.WriteFile(envConfigFile, envData, 0600); err != nil {
+		t.Fatalf("Failed to write env config: %v", err)
+	}
+
+	appSettings := New[TestConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvironment("dev").
+		WithEnvVars([]string{"DATABASEURL=postgres://localhost/env"})
+
+	configMap := make(map[string]interface{})
+	if err := appSettings.loadConfigFile(baseConfigFile, configMap); err != nil {
+		t.Fatalf("loadConfigFile() returned error: %v", err)
+	}
+	if err := appSettings.loadConfigFile(envConfigFile, configMap); err != nil {
+		t.Fatalf("loadConfigFile() returned error: %v", err)
+	}
+
+	expectedMap := map[string]interface{}{
+		"databaseURL": "postgres://localhost/dev",
+		"port":        json.Number("8001"),
+	}
+	if !reflect.DeepEqual(configMap, expectedMap) {
+		t.Errorf("Expected config %v, got %v", expectedMap, configMap)
+	}
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.DatabaseURL != "postgres://localhost/env" {
+		t.Errorf("Expected env var to take precedence, got %q", result.DatabaseURL)
+	}
+
+	if result.Port != 8001 {
+		t.Errorf("Expected port 8001, got %d", result.Port)
+	}
+}
+
+func TestLoad_DuplicateKeysWarning(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	baseData := []byte(`{"databaseurl": "postgres://localhost/lower", "databaseURL": "postgres://localhost/exact"}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	var logs bytes.Buffer
+	appSettings := New[TestConfig]().
+		WithConfigDirectory(tempDir).
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))).
+		WithEnvVars([]string{"port=1000", "PORT=2000"}).
+		WithArgs([]string{"program", "--Name", "a", "--name", "b"})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.DatabaseURL != "postgres://localhost/exact" {
+		t.Errorf("Expected exact key to take precedence, got %q", result.DatabaseURL)
+	}
+
+	if result.Port != 2000 || result.Name != "b" {
+		t.Errorf("Expected last key to take precedence, got port %d and name %q", result.Port, result.Name)
+	}
+
+	output := logs.String()
+	for _, expected := range []string{
+		`keys \"databaseurl\" and \"databaseURL\"`,
+		`keys \"port\" and \"PORT\"`,
+		`keys \"Name\" and \"name\"`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected warning containing %s, got: %s", expected, output)
+		}
+	}
+
+	if strings.Count(output, "level=WARN") != 3 {
+		t.Errorf("Expected 3 warnings, got: %s", output)
+	}
+}
+
+func TestLoad_DuplicateUnrelatedKeysNoWarning(t *testing.T) {
+	var logs bytes.Buffer
+	_, err := New[TestConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))).
+		WithEnvVars([]string{"http_proxy=http://a", "HTTP_PROXY=http://a", "no_proxy=b", "NO_PROXY=b"}).
+		WithArgs([]string{"--Verbose", "--verbose"}).
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if logs.Len() != 0 {
+		t.Errorf("Expected no warnings for keys outside of the config type, got: %s", logs.String())
+	}
+}
+
+type ConfigTagPool struct {
+	MaxConns int `config:"maxConns" json:"max_conns"`
+}
+
+type ConfigTagConfig struct {
+	Database string        `config:"database" json:"db,omitempty"`
+	Pool     ConfigTagPool `config:",inline" json:"pool"`
+	Token    string        `config:"-" json:"token"`
+	Region   string        `json:"region"`
+}
+
+func TestLoad_ConfigTags(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	baseData := []byte(`{"database": "postgres://localhost/base", "db": "ignored", "token": "ignored", "region": "eu"}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	appSettings := New[ConfigTagConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvVars([]string{"MAXCONNS=10", "TOKEN=ignored"}).
+		WithArgs([]string{"program", "--database", "postgres://localhost/args"})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := ConfigTagConfig{
+		Database: "postgres://localhost/args",
+		Pool:     ConfigTagPool{MaxConns: 10},
+		Region:   "eu",
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, *result)
+	}
+}
+
+type LoadIntoConfig struct {
+	Name      string            `json:"name"`
+	Port      int               `json:"port"`
+	Database  DBConfig          `json:"database"`
+	Labels    map[string]string `json:"labels"`
+	Upstreams []Upstream        `json:"upstreams"`
+	Retries   *int              `json:"retries"`
+}
+
+func TestLoadInto_OverlaysExistingValues(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	baseData := []byte(`{"database": {"host": "db.example.com"}, "labels": {"team": "platform"}, "retries": 5}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	retries := 3
+	config := LoadIntoConfig{
+		Name:      "default",
+		Port:      8080,
+		Database:  DBConfig{Host: "localhost", Port: 5432},
+		Labels:    map[string]string{"app": "api"},
+		Upstreams: []Upstream{{Host: "a", Port: 80}},
+		Retries:   &retries,
+	}
+
+	err := New[LoadIntoConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvVars([]string{"PORT=9090"}).
+		LoadInto(&config)
+	if err != nil {
+		t.Fatalf("LoadInto() returned error: %v", err)
+	}
+
+	expected := LoadIntoConfig{
+		Name:      "default",
+		Port:      9090,
+		Database:  DBConfig{Host: "db.example.com", Port: 5432},
+		Labels:    map[string]string{"app": "api", "team": "platform"},
+		Upstreams: []Upstream{{Host: "a", Port: 80}},
+		Retries:   &retries,
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, config)
+	}
+
+	if retries != 5 {
+		t.Errorf("Expected existing pointer target to be overwritten, got %d", retries)
+	}
+}
+
+func TestLoadInto_IndexedOverrides(t *testing.T) {
+	type Config struct {
+		Upstreams []Upstream `json:"upstreams"`
+		Arr       [3]int     `json:"arr"`
+		Tags      []string   `json:"tags"`
+	}
+
+	config := Config{
+		Upstreams: []Upstream{{Host: "a", Port: 80}, {Host: "b", Port: 80}},
+		Arr:       [3]int{1, 2, 3},
+		Tags:      []string{"x", "y"},
+	}
+	upstreams := config.Upstreams
+
+	err := New[Config]().
+		WithConfigDirectory(t.TempDir()).
+		WithEnvVars([]string{"UPSTREAMS__1__PORT=81", "ARR__1=9", "TAGS__3=z"}).
+		LoadInto(&config)
+	if err != nil {
+		t.Fatalf("LoadInto() returned error: %v", err)
+	}
+
+	expected := Config{
+		Upstreams: []Upstream{{Host: "a", Port: 80}, {Host: "b", Port: 81}},
+		Arr:       [3]int{1, 9, 3},
+		Tags:      []string{"x", "y", "", "z"},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, config)
+	}
+
+	if upstreams[1].Port != 80 {
+		t.Errorf("Expected previous slice to be left unchanged, got %+v", upstreams)
+	}
+}
+
+func TestLoadInto_FileListReplacesLongerDefault(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "config.json"), []byte(`{"upstreams": [{"host": "c"}]}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config := LoadIntoConfig{Upstreams: []Upstream{{Host: "a", Port: 80}, {Host: "b", Port: 81}}}
+	if err := New[LoadIntoConfig]().WithConfigDirectory(tempDir).LoadInto(&config); err != nil {
+		t.Fatalf("LoadInto() returned error: %v", err)
+	}
+
+	expected := []Upstream{{Host: "c", Port: 80}}
+	if !reflect.DeepEqual(config.Upstreams, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, config.Upstreams)
+	}
+}
+
+func TestLoadInto_NilDestination(t *testing.T) {
+	if err := New[TestConfig]().WithConfigDirectory(t.TempDir()).LoadInto(nil); err == nil {
+		t.Error("Expected error for nil destination, got nil")
+	}
+}
+
+func TestLoad_WithFile(t *testing.T) {
+	tempDir := t.TempDir()
+	otherDir := t.TempDir()
+
+	files := map[string]string{
+		filepath.Join(tempDir, "config.json"):     `{"databaseURL": "postgres://localhost/base", "name": "base"}`,
+		filepath.Join(tempDir, "config.dev.json"): `{"port": 8001}`,
+		filepath.Join(tempDir, "secrets.yaml"):    "databaseURL: postgres://localhost/secrets\n",
+		filepath.Join(otherDir, "overrides.toml"): "name = \"overrides\"\nport = 8002\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	result, err := New[TestConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvironment("dev").
+		WithFile("secrets.yaml", FileRequired).
+		WithFile(filepath.Join(otherDir, "overrides.toml"), FileOptional).
+		WithFile("missing.json", FileOptional).
+		WithEnvVars([]string{"PORT=9090"}).
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := TestConfig{DatabaseURL: "postgres://localhost/secrets", Name: "overrides", Port: 9090}
+	if *result != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *result)
+	}
+}
+
+func TestLoad_WithFileRequiredMissing(t *testing.T) {
+	tempDir := t.TempDir()
+
+	_, err := New[TestConfig]().
+		WithConfigDirectory(tempDir).
+		WithFile("secrets.yaml", FileRequired).
+		Load()
+
+	expected := fmt.Sprintf(`failed to load file "secrets.yaml": required config file not found in %q, expected secrets.yaml`, tempDir)
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+}
+
+func TestLoad_WithFileUnsupportedFormat(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "settings.txt"), []byte("port=1"), 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	_, err := New[TestConfig]().WithConfigDirectory(tempDir).WithFile("settings.txt", FileOptional).Load()
+	if err == nil || !strings.Contains(err.Error(), `unsupported config file format ".txt"`) {
+		t.Errorf("Expected unsupported format error, got %v", err)
+	}
+}
+
+func TestLoad_WithRequiredConfigFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "config.yaml"), []byte("port: 8000\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "config.prod.json"), []byte(`{"port": 8001}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	result, err := New[TestConfig]().WithConfigDirectory(tempDir).WithEnvironment("prod").WithRequiredConfigFiles().Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if result.Port != 8001 {
+		t.Errorf("Expected port from env config, got %d", result.Port)
+	}
+
+	_, err = New[TestConfig]().WithConfigDirectory(tempDir).WithEnvironment("prd").WithRequiredConfigFiles().Load()
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to load env config: required config file not found") ||
+		!strings.Contains(err.Error(), "config.prd.json") {
+		t.Errorf("Expected missing env config error, got %v", err)
+	}
+
+	_, err = New[TestConfig]().WithConfigDirectory(t.TempDir()).WithRequiredConfigFiles().Load()
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to load base config: required config file not found") {
+		t.Errorf("Expected missing base config error, got %v", err)
+	}
+}
+
+func TestLoad_MissingConfigFilesOptionalByDefault(t *testing.T) {
+	if _, err := New[TestConfig]().WithConfigDirectory(t.TempDir()).WithEnvironment("prd").Load(); err != nil {
+		t.Errorf("Expected missing config files to be ignored by default, got %v", err)
+	}
+}
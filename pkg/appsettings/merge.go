@@ -0,0 +1,173 @@
+package appsettings
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// envPathSeparator separates nested keys in environment variable names (e.g., UPSTREAMS__0__HOST).
+	envPathSeparator = "__"
+	// argPathSeparator separates nested keys in command line argument names (e.g., --upstreams.0.host).
+	argPathSeparator = "."
+	// maxArrayIndexGap is the number of elements an array can be extended by beyond its current length
+	// when setting an element by index, so that a mistyped index cannot allocate huge arrays.
+	maxArrayIndexGap = 1000
+)
+
+// splitKeyPath splits a key into its path segments using the given separator.
+// Keys containing empty segments are kept as a single flat key.
+func splitKeyPath(key string, separator string) []string {
+	segments := strings.Split(key, separator)
+	for _, segment := range segments {
+		if segment == "" {
+			return []string{key}
+		}
+	}
+	return segments
+}
+
+// setPath sets value at the given path in configMap, creating intermediate maps and arrays as needed.
+// Numeric segments address array elements; existing values along the path are preserved.
+// Indexes more than maxArrayIndexGap elements beyond the end of an array are rejected.
+func setPath(configMap map[string]interface{}, path []string, value interface{}) error {
+	return setTypedPath(configMap, path, nil, value)
+}
+
+// setTypedPath sets value at the given path in configMap like setPath, where containers holds the type each
+// key of the path is looked up in (see resolvePath). Numeric keys only address array elements of slice, array
+// and interface types, and keys with a nil type lie outside the config type and address map entries.
+func setTypedPath(configMap map[string]interface{}, path []string, containers []reflect.Type, value interface{}) error {
+	if len(path) == 0 {
+		return nil
+	}
+
+	node, err := setPathValue(configMap[path[0]], path[1:], nextContainers(containers), value)
+	if err != nil {
+		return err
+	}
+	configMap[path[0]] = node
+	return nil
+}
+
+// setPathValue returns node with value set at the given path, where containers holds the types of the keys
+// of path or is nil if they are unknown.
+func setPathValue(node interface{}, path []string, containers []reflect.Type, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	key := path[0]
+	if index, err := strconv.Atoi(key); err == nil && index >= 0 && isIndexContainer(containers) {
+		if list, ok := node.([]interface{}); ok || node == nil {
+			if index > len(list)+maxArrayIndexGap {
+				return nil, fmt.Errorf("array index %d out of range, expected at most %d for an array of length %d",
+					index, len(list)+maxArrayIndexGap, len(list))
+			}
+			for len(list) <= index {
+				list = append(list, nil)
+			}
+
+			elem, err := setPathValue(list[index], path[1:], nextContainers(containers), value)
+			if err != nil {
+				return nil, err
+			}
+			list[index] = elem
+			return list, nil
+		}
+	}
+
+	nodeMap, ok := node.(map[string]interface{})
+	if !ok {
+		nodeMap = make(map[string]interface{})
+	}
+	elem, err := setPathValue(nodeMap[key], path[1:], nextContainers(containers), value)
+	if err != nil {
+		return nil, err
+	}
+	nodeMap[key] = elem
+	return nodeMap, nil
+}
+
+// isIndexContainer reports whether a numeric key looked up in the first type of containers addresses an
+// array element, i.e., the type is unknown (nil containers), a slice, an array or an interface.
+func isIndexContainer(containers []reflect.Type) bool {
+	if containers == nil {
+		return true
+	}
+	if len(containers) == 0 || containers[0] == nil {
+		return false
+	}
+
+	switch containers[0].Kind() {
+	case reflect.Slice, reflect.Array, reflect.Interface:
+		return true
+	default:
+		return false
+	}
+}
+
+// nextContainers returns the types of the keys following the first one, keeping nil for unknown types.
+func nextContainers(containers []reflect.Type) []reflect.Type {
+	if len(containers) == 0 {
+		return containers
+	}
+	return containers[1:]
+}
+
+// mergeMaps deep merges src into dst. Nested maps are merged recursively,
+// all other values (including arrays) in src replace those in dst.
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, srcValue := range src {
+		srcMap, srcIsMap := srcValue.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[key] = srcValue
+	}
+}
+
+// lookupPath returns the value at the given path in configMap, or nil if the path does not exist.
+func lookupPath(configMap map[string]interface{}, path []string) interface{} {
+	var node interface{} = configMap
+	for _, key := range path {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			node = value[key]
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil
+			}
+			node = value[index]
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+// cloneValue returns a deep copy of raw, copying nested maps and arrays so that the result can be modified
+// without affecting raw.
+func cloneValue(raw interface{}) interface{} {
+	switch value := raw.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, elem := range value {
+			result[key] = cloneValue(elem)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, elem := range value {
+			result[i] = cloneValue(elem)
+		}
+		return result
+	default:
+		return raw
+	}
+}
@@ -0,0 +1,149 @@
+package appsettings
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitKeyPath(t *testing.T) {
+	tests := []struct {
+		key       string
+		separator string
+		expected  []string
+	}{
+		{"port", envPathSeparator, []string{"port"}},
+		{"upstreams__0__host", envPathSeparator, []string{"upstreams", "0", "host"}},
+		{"database_url", envPathSeparator, []string{"database_url"}},
+		{"__cf_user_text_encoding", envPathSeparator, []string{"__cf_user_text_encoding"}},
+		{"upstreams.1.port", argPathSeparator, []string{"upstreams", "1", "port"}},
+		{"debug-mode", argPathSeparator, []string{"debug-mode"}},
+		{"trailing.", argPathSeparator, []string{"trailing."}},
+	}
+
+	for _, test := range tests {
+		result := splitKeyPath(test.key, test.separator)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("splitKeyPath(%q, %q) = %v, expected %v", test.key, test.separator, result, test.expected)
+		}
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	configMap := map[string]interface{}{
+		"upstreams": []interface{}{
+			map[string]interface{}{"host": "a", "port": float64(80)},
+		},
+	}
+
+	paths := []struct {
+		path  []string
+		value interface{}
+	}{
+		{[]string{"upstreams", "0", "host"}, "b"},
+		{[]string{"upstreams", "2", "port"}, 82},
+		{[]string{"database", "pool", "max"}, 50},
+		{[]string{"port"}, 8080},
+	}
+	for _, p := range paths {
+		if err := setPath(configMap, p.path, p.value); err != nil {
+			t.Fatalf("setPath(%v) returned error: %v", p.path, err)
+		}
+	}
+
+	expected := map[string]interface{}{
+		"upstreams": []interface{}{
+			map[string]interface{}{"host": "b", "port": float64(80)},
+			nil,
+			map[string]interface{}{"port": 82},
+		},
+		"database": map[string]interface{}{
+			"pool": map[string]interface{}{"max": 50},
+		},
+		"port": 8080,
+	}
+
+	if !reflect.DeepEqual(configMap, expected) {
+		t.Errorf("Expected config %v, got %v", expected, configMap)
+	}
+}
+
+func TestSetPath_NumericKeyInMap(t *testing.T) {
+	configMap := map[string]interface{}{
+		"ports": map[string]interface{}{"0": "zero"},
+	}
+
+	if err := setPath(configMap, []string{"ports", "1"}, "one"); err != nil {
+		t.Fatalf("setPath() returned error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"ports": map[string]interface{}{"0": "zero", "1": "one"},
+	}
+
+	if !reflect.DeepEqual(configMap, expected) {
+		t.Errorf("Expected config %v, got %v", expected, configMap)
+	}
+}
+
+func TestSetPath_IndexOutOfRange(t *testing.T) {
+	configMap := map[string]interface{}{
+		"upstreams": []interface{}{"a", "b"},
+	}
+
+	if err := setPath(configMap, []string{"upstreams", "1002", "host"}, "c"); err != nil {
+		t.Fatalf("setPath() returned error for index within range: %v", err)
+	}
+
+	err := setPath(configMap, []string{"upstreams", "1000000000", "host"}, "c")
+	expected := "array index 1000000000 out of range, expected at most 2003 for an array of length 1003"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+
+	if err := setPath(configMap, []string{"ports", "1001"}, 1); err == nil {
+		t.Error("Expected error for index out of range of a new array, got nil")
+	}
+}
+
+func TestMergeMaps(t *testing.T) {
+	dst := map[string]interface{}{
+		"database": map[string]interface{}{"host": "localhost", "port": float64(5432)},
+		"features": []interface{}{"a", "b"},
+		"name":     "base",
+	}
+	src := map[string]interface{}{
+		"database": map[string]interface{}{"host": "dev-server"},
+		"features": []interface{}{"c"},
+	}
+
+	mergeMaps(dst, src)
+
+	expected := map[string]interface{}{
+		"database": map[string]interface{}{"host": "dev-server", "port": float64(5432)},
+		"features": []interface{}{"c"},
+		"name":     "base",
+	}
+
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("Expected config %v, got %v", expected, dst)
+	}
+}
+
+func TestCloneValue(t *testing.T) {
+	original := map[string]interface{}{
+		"database":  map[string]interface{}{"host": "localhost"},
+		"upstreams": []interface{}{map[string]interface{}{"port": 80}},
+	}
+
+	clone, _ := cloneValue(original).(map[string]interface{})
+	if !reflect.DeepEqual(clone, original) {
+		t.Fatalf("Expected clone %v to equal original %v", clone, original)
+	}
+
+	clone["database"].(map[string]interface{})["host"] = "changed"
+	clone["upstreams"].([]interface{})[0].(map[string]interface{})["port"] = 81
+	if original["database"].(map[string]interface{})["host"] != "localhost" ||
+		original["upstreams"].([]interface{})[0].(map[string]interface{})["port"] != 80 {
+		t.Errorf("Expected original to be unchanged, got %v", original)
+	}
+}
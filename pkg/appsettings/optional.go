@@ -0,0 +1,87 @@
+package appsettings
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Optional wraps a config value and records whether any config source provided it.
+// It distinguishes "not configured" from the zero value (e.g., port 0 or debug false),
+// so that applications can apply their own fallbacks. An explicit null leaves it unset,
+// just like a pointer field stays nil.
+type Optional[T any] struct {
+	value T
+	set   bool
+}
+
+// Some creates an Optional holding value.
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value: value, set: true}
+}
+
+// IsSet reports whether a value was provided.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// Get returns the value and whether it was provided.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.set
+}
+
+// Value returns the value, or the zero value of T if it was not provided.
+func (o Optional[T]) Value() T {
+	return o.value
+}
+
+// OrElse returns the value if it was provided and fallback otherwise.
+func (o Optional[T]) OrElse(fallback T) T {
+	if !o.set {
+		return fallback
+	}
+	return o.value
+}
+
+// MarshalJSON implements json.Marshaler and returns null if no value was provided.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.set {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. A null value leaves the Optional unset.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*o = Optional[T]{}
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*o = Some(value)
+	return nil
+}
+
+// wrappedType returns the type of the wrapped value.
+func (o Optional[T]) wrappedType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// decodeRaw decodes a raw config value into the wrapped value and marks it as provided.
+// A null value leaves the Optional unset.
+func (o *Optional[T]) decodeRaw(sch *schema, raw interface{}, path string) error {
+	if raw == nil {
+		*o = Optional[T]{}
+		return nil
+	}
+
+	value := o.value
+	if err := sch.decodeValue(raw, reflect.ValueOf(&value).Elem(), path); err != nil {
+		return err
+	}
+	*o = Some(value)
+	return nil
+}
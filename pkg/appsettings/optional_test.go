@@ -0,0 +1,138 @@
+package appsettings
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type OptionalConfig struct {
+	Port      Optional[int]      `json:"port"`
+	DebugMode Optional[bool]     `json:"debugMode"`
+	Name      Optional[string]   `json:"name"`
+	Upstream  Optional[Upstream] `json:"upstream"`
+	Timeout   *float64           `json:"timeout"`
+	Retries   *int               `json:"retries"`
+	Missing   Optional[int]      `json:"missing"`
+	Nulled    Optional[string]   `json:"nulled"`
+}
+
+func TestLoad_Optional(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	baseData := []byte(`{"port": 0, "debugMode": false, "retries": 0, "nulled": null, "upstream": {"host": "a"}}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	appSettings := New[OptionalConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvVars([]string{"NAME=app", "UPSTREAM__PORT=81"})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if port, ok := result.Port.Get(); !ok || port != 0 {
+		t.Errorf("Expected port to be set to 0, got (%d, %v)", port, ok)
+	}
+
+	if debug, ok := result.DebugMode.Get(); !ok || debug {
+		t.Errorf("Expected debug mode to be set to false, got (%v, %v)", debug, ok)
+	}
+
+	if result.Name.Value() != "app" || !result.Name.IsSet() {
+		t.Errorf("Expected name to be set to 'app', got %+v", result.Name)
+	}
+
+	if result.Upstream.Value() != (Upstream{Host: "a", Port: 81}) {
+		t.Errorf("Expected upstream to be merged from file and env, got %+v", result.Upstream)
+	}
+
+	if result.Missing.IsSet() || result.Missing.OrElse(8080) != 8080 {
+		t.Errorf("Expected missing to be unset, got %+v", result.Missing)
+	}
+
+	if result.Nulled.IsSet() {
+		t.Errorf("Expected null value to leave nulled unset, got %+v", result.Nulled)
+	}
+
+	if result.Timeout != nil {
+		t.Errorf("Expected timeout pointer to be nil, got %v", *result.Timeout)
+	}
+
+	if result.Retries == nil || *result.Retries != 0 {
+		t.Errorf("Expected retries pointer to be set to 0, got %v", result.Retries)
+	}
+}
+
+func TestLoad_OptionalZeroFromEnvVarsAndArgs(t *testing.T) {
+	tests := map[string]*AppSettings[OptionalConfig]{
+		"env vars": New[OptionalConfig]().WithEnvVars([]string{"PORT=0", "RETRIES=1", "UPSTREAM__PORT=0"}),
+		"args":     New[OptionalConfig]().WithArgs([]string{"--port", "0", "--retries", "1", "--upstream.port", "0"}),
+		"set":      New[OptionalConfig]().WithArgs([]string{"--set", "port=0", "--set", "retries=1", "--set", "upstream.port=0"}),
+	}
+
+	for name, appSettings := range tests {
+		result, err := appSettings.WithConfigDirectory(t.TempDir()).Load()
+		if err != nil {
+			t.Fatalf("%s: Load() returned error: %v", name, err)
+		}
+
+		if port, ok := result.Port.Get(); !ok || port != 0 {
+			t.Errorf("%s: expected port to be set to 0, got (%d, %v)", name, port, ok)
+		}
+		if result.Retries == nil || *result.Retries != 1 {
+			t.Errorf("%s: expected retries pointer to be set to 1, got %v", name, result.Retries)
+		}
+		if upstream, ok := result.Upstream.Get(); !ok || upstream.Port != 0 {
+			t.Errorf("%s: expected upstream port to be set to 0, got (%+v, %v)", name, upstream, ok)
+		}
+	}
+}
+
+func TestOptional_Accessors(t *testing.T) {
+	var unset Optional[int]
+	if value, ok := unset.Get(); ok || value != 0 {
+		t.Errorf("Expected unset optional, got (%d, %v)", value, ok)
+	}
+
+	if unset.OrElse(5) != 5 {
+		t.Errorf("Expected fallback 5, got %d", unset.OrElse(5))
+	}
+
+	set := Some(0)
+	if !set.IsSet() || set.OrElse(5) != 0 {
+		t.Errorf("Expected set optional with 0, got %+v", set)
+	}
+}
+
+func TestOptional_JSON(t *testing.T) {
+	var result struct {
+		A Optional[int] `json:"a"`
+		B Optional[int] `json:"b"`
+		C Optional[int] `json:"c"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"a": 0, "b": null}`), &result); err != nil {
+		t.Fatalf("json.Unmarshal() returned error: %v", err)
+	}
+
+	if !result.A.IsSet() || result.B.IsSet() || result.C.IsSet() {
+		t.Errorf("Expected only a to be set, got %+v", result)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+
+	expected := `{"a":0,"b":null,"c":null}`
+	if !reflect.DeepEqual(string(data), expected) {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
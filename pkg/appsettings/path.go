@@ -0,0 +1,64 @@
+package appsettings
+
+import (
+	"path/filepath"
+	"reflect"
+)
+
+const (
+	// pathTagName is the struct tag marking string fields holding file system paths.
+	pathTagName = "path"
+	// pathTagRelative marks a field whose relative paths are resolved against the directory of their source.
+	pathTagRelative = "relative"
+)
+
+// Path is a file system path. Relative paths from a config file are resolved against the directory
+// of that file, relative paths from any other source against the working directory. String fields
+// can opt into the same behavior with the `path:"relative"` tag.
+type Path string
+
+//nolint:gochecknoglobals // immutable reflection type
+var pathType = reflect.TypeFor[Path]()
+
+// isPathField reports whether values of type t held by field are resolved as paths, either because
+// they are of type Path or because field is tagged with `path:"relative"`. Arrays, slices and maps
+// are resolved element-wise. field may be nil if t is not reached through a struct field.
+func isPathField(field *reflect.StructField, t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	t = elemType(t)
+	for t.Kind() == reflect.Array || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = elemType(t.Elem())
+	}
+	if t == pathType {
+		return true
+	}
+	return field != nil && field.Tag.Get(pathTagName) == pathTagRelative && t.Kind() == reflect.String
+}
+
+// absolutePath joins a relative path onto dir. Empty and absolute paths are returned unchanged.
+func absolutePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// resolvePaths resolves all relative path strings in raw, including those nested in arrays and objects, against dir.
+func resolvePaths(raw interface{}, dir string) interface{} {
+	switch value := raw.(type) {
+	case string:
+		return absolutePath(dir, value)
+	case []interface{}:
+		for i, elem := range value {
+			value[i] = resolvePaths(elem, dir)
+		}
+	case map[string]interface{}:
+		for key, elem := range value {
+			value[key] = resolvePaths(elem, dir)
+		}
+	default:
+	}
+	return raw
+}
@@ -0,0 +1,119 @@
+package appsettings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type TLSConfig struct {
+	CertFile Path              `json:"certFile"`
+	KeyFile  string            `json:"keyFile" path:"relative"`
+	CAFiles  []string          `json:"caFiles" path:"relative" env:"json"`
+	Mounts   map[string]Path   `json:"mounts"`
+	Optional Optional[Path]    `json:"optional"`
+	Name     string            `json:"name"`
+	Labels   map[string]string `json:"labels"`
+}
+
+type PathConfig struct {
+	TLS     TLSConfig `json:"tls"`
+	DataDir Path      `json:"dataDir"`
+}
+
+func TestIsPathField(t *testing.T) {
+	configType := reflect.TypeFor[TLSConfig]()
+
+	tests := map[string]bool{
+		"CertFile": true,
+		"KeyFile":  true,
+		"CAFiles":  true,
+		"Mounts":   true,
+		"Optional": true,
+		"Name":     false,
+		"Labels":   false,
+	}
+
+	for name, expected := range tests {
+		field, _ := configType.FieldByName(name)
+		if result := isPathField(&field, field.Type); result != expected {
+			t.Errorf("isPathField(%s) = %v, expected %v", name, result, expected)
+		}
+	}
+
+	if isPathField(nil, nil) {
+		t.Error("Expected isPathField(nil, nil) to be false")
+	}
+}
+
+func TestAbsolutePath(t *testing.T) {
+	dir := filepath.Join(string(filepath.Separator), "etc", "app")
+	absolute := filepath.Join(string(filepath.Separator), "var", "cert.pem")
+
+	tests := map[string]string{
+		"":                            "",
+		"cert.pem":                    filepath.Join(dir, "cert.pem"),
+		filepath.Join("..", "ca.pem"): filepath.Join(string(filepath.Separator), "etc", "ca.pem"),
+		absolute:                      absolute,
+	}
+
+	for path, expected := range tests {
+		if result := absolutePath(dir, path); result != expected {
+			t.Errorf("absolutePath(%q) = %q, expected %q", path, result, expected)
+		}
+	}
+}
+
+func TestLoad_RelativePaths(t *testing.T) {
+	tempDir := t.TempDir()
+	configDir := filepath.Join(tempDir, "config")
+	if err := os.Mkdir(configDir, 0700); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+
+	baseConfigFile := filepath.Join(configDir, "config.json")
+	baseData := []byte(`{
+		"tls": {
+			"certFile": "certs/server.pem",
+			"keyFile": "/etc/ssl/server.key",
+			"mounts": {"data": "data"},
+			"optional": "optional.pem",
+			"name": "server.pem"
+		},
+		"dataDir": "data"
+	}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	appSettings := New[PathConfig]().
+		WithConfigDirectory(configDir).
+		WithEnvVars([]string{"TLS__KEYFILE=server.key", `TLS__CAFILES=["ca.pem"]`}).
+		WithArgs([]string{"program", "--dataDir", "1"})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := PathConfig{
+		TLS: TLSConfig{
+			CertFile: Path(filepath.Join(configDir, "certs", "server.pem")),
+			KeyFile:  filepath.Join(cwd, "server.key"),
+			CAFiles:  []string{filepath.Join(cwd, "ca.pem")},
+			Mounts:   map[string]Path{"data": Path(filepath.Join(configDir, "data"))},
+			Optional: Some(Path(filepath.Join(configDir, "optional.pem"))),
+			Name:     "server.pem",
+		},
+		DataDir: Path(filepath.Join(cwd, "1")),
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, *result)
+	}
+}
@@ -0,0 +1,160 @@
+package appsettings
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const (
+	// prefixTagName is the struct tag namespacing the environment variable and argument names of a struct field.
+	prefixTagName = "prefix"
+	// argPrefixSeparator separates a prefix from the field name in command line argument names (e.g., --replica-host).
+	argPrefixSeparator = "-"
+)
+
+// prefixTable maps the environment variable and argument namespaces of fields tagged with `prefix`
+// to their key paths in the config tree.
+type prefixTable struct {
+	env  map[string][]string
+	args map[string][]string
+}
+
+// newPrefixTable collects the namespaces of all fields of type t tagged with `prefix`. A prefix is
+// relative to the namespace of the parent field, which is its config key if the parent has no prefix.
+// It fails if two fields share a namespace, as their environment variables or arguments would be ambiguous.
+func newPrefixTable(t reflect.Type) (*prefixTable, error) {
+	p := &prefixTable{env: make(map[string][]string), args: make(map[string][]string)}
+	if err := p.collect(t, nil, "", "", false, make(map[reflect.Type]bool)); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// collect records the namespaces of the tagged fields of t, which is located at path and whose
+// environment variable and argument namespaces are envName and argName. prefixed reports whether
+// the argument namespace ends with a prefix, which is followed by a hyphen instead of a dot.
+func (p *prefixTable) collect(
+	t reflect.Type, path []string, envName, argName string, prefixed bool, visiting map[reflect.Type]bool,
+) error {
+	t = elemType(t)
+	if t.Kind() != reflect.Struct || visiting[t] {
+		return nil
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, inline, ok := fieldKey(field)
+		if !ok {
+			continue
+		}
+
+		// Fields of inlined structs are promoted into the same namespace
+		if inline {
+			if err := p.collect(field.Type, path, envName, argName, prefixed, visiting); err != nil {
+				return err
+			}
+			continue
+		}
+
+		argSeparator := argPathSeparator
+		if prefixed {
+			argSeparator = argPrefixSeparator
+		}
+
+		fieldPath := append(slices.Clip(path), name)
+		fieldEnvName := joinName(envName, strings.ToLower(name), envPathSeparator)
+		fieldArgName := joinName(argName, strings.ToLower(name), argSeparator)
+		prefix := strings.ToLower(field.Tag.Get(prefixTagName))
+		if prefix != "" {
+			fieldEnvName = joinName(envName, prefix, envPathSeparator)
+			fieldArgName = joinName(argName, prefix, argSeparator)
+			if err := addPrefix(p.env, fieldEnvName, fieldPath, "environment variable"); err != nil {
+				return err
+			}
+			if err := addPrefix(p.args, fieldArgName, fieldPath, "argument"); err != nil {
+				return err
+			}
+		}
+
+		if err := p.collect(field.Type, fieldPath, fieldEnvName, fieldArgName, prefix != "", visiting); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addPrefix records the key path of the field with the namespace name in prefixes.
+// It fails if the namespace is already taken by another field.
+func addPrefix(prefixes map[string][]string, name string, path []string, kind string) error {
+	if other, exists := prefixes[name]; exists {
+		return fmt.Errorf("fields %q and %q share the %s prefix %q",
+			strings.Join(other, argPathSeparator), strings.Join(path, argPathSeparator), kind, name)
+	}
+	prefixes[name] = path
+	return nil
+}
+
+// envPath splits a lowercase environment variable name into its key path, expanding prefixes.
+func (p *prefixTable) envPath(key string) []string {
+	return expandPrefix(p.env, key, []string{envPathSeparator}, envPathSeparator)
+}
+
+// argPath splits a lowercase argument name into its key path, expanding prefixes.
+func (p *prefixTable) argPath(key string) []string {
+	return expandPrefix(p.args, key, []string{argPrefixSeparator, argPathSeparator}, argPathSeparator)
+}
+
+// expandPrefix replaces the longest prefix of key found in prefixes, followed by one of the separators,
+// with its key path and splits the remainder by pathSeparator.
+func expandPrefix(prefixes map[string][]string, key string, separators []string, pathSeparator string) []string {
+	var (
+		path []string
+		rest string
+		size = -1
+	)
+
+	for prefix, prefixPath := range prefixes {
+		if len(prefix) <= size {
+			continue
+		}
+		if remainder, ok := trimPrefix(key, prefix, separators); ok {
+			path, rest, size = prefixPath, remainder, len(prefix)
+		}
+	}
+
+	if size < 0 {
+		return splitKeyPath(key, pathSeparator)
+	}
+
+	path = slices.Clone(path)
+	if rest != "" {
+		path = append(path, splitKeyPath(rest, pathSeparator)...)
+	}
+	return path
+}
+
+// trimPrefix returns the remainder of key after prefix and one of the separators.
+// It reports false if key neither equals prefix nor starts with prefix followed by a separator.
+func trimPrefix(key, prefix string, separators []string) (string, bool) {
+	if key == prefix {
+		return "", true
+	}
+	for _, separator := range separators {
+		if rest, ok := strings.CutPrefix(key, prefix+separator); ok && rest != "" {
+			return rest, true
+		}
+	}
+	return "", false
+}
+
+// joinName appends name to the namespace using separator.
+func joinName(namespace, name, separator string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + separator + name
+}
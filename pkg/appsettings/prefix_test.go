@@ -0,0 +1,149 @@
+package appsettings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type DBPoolConfig struct {
+	Max int `json:"max"`
+}
+
+type DBConfig struct {
+	Host string       `json:"host"`
+	Port int          `json:"port"`
+	Pool DBPoolConfig `json:"pool" prefix:"pool"`
+}
+
+type PrefixConfig struct {
+	Primary DBConfig  `json:"primary" prefix:"primary"`
+	Replica *DBConfig `json:"replicaDatabase" prefix:"replica"`
+	Cache   struct {
+		Backup DBConfig `json:"backup" prefix:"bak"`
+	} `json:"cache"`
+}
+
+func TestNewPrefixTable(t *testing.T) {
+	table, err := newPrefixTable(reflect.TypeFor[PrefixConfig]())
+	if err != nil {
+		t.Fatalf("newPrefixTable() returned error: %v", err)
+	}
+
+	expectedEnv := map[string][]string{
+		"primary":          {"primary"},
+		"primary__pool":    {"primary", "pool"},
+		"replica":          {"replicaDatabase"},
+		"replica__pool":    {"replicaDatabase", "pool"},
+		"cache__bak":       {"cache", "backup"},
+		"cache__bak__pool": {"cache", "backup", "pool"},
+	}
+	if !reflect.DeepEqual(table.env, expectedEnv) {
+		t.Errorf("Expected env prefixes %v, got %v", expectedEnv, table.env)
+	}
+
+	expectedArgs := map[string][]string{
+		"primary":        {"primary"},
+		"primary-pool":   {"primary", "pool"},
+		"replica":        {"replicaDatabase"},
+		"replica-pool":   {"replicaDatabase", "pool"},
+		"cache.bak":      {"cache", "backup"},
+		"cache.bak-pool": {"cache", "backup", "pool"},
+	}
+	if !reflect.DeepEqual(table.args, expectedArgs) {
+		t.Errorf("Expected arg prefixes %v, got %v", expectedArgs, table.args)
+	}
+}
+
+func TestPrefixTable_Paths(t *testing.T) {
+	table, err := newPrefixTable(reflect.TypeFor[PrefixConfig]())
+	if err != nil {
+		t.Fatalf("newPrefixTable() returned error: %v", err)
+	}
+
+	envTests := map[string][]string{
+		"replica__host":      {"replicaDatabase", "host"},
+		"replica__pool__max": {"replicaDatabase", "pool", "max"},
+		"replica":            {"replicaDatabase"},
+		"replicas__host":     {"replicas", "host"},
+		"cache__bak__port":   {"cache", "backup", "port"},
+		"port":               {"port"},
+	}
+	for key, expected := range envTests {
+		if result := table.envPath(key); !reflect.DeepEqual(result, expected) {
+			t.Errorf("envPath(%q) = %v, expected %v", key, result, expected)
+		}
+	}
+
+	argTests := map[string][]string{
+		"replica-host":     {"replicaDatabase", "host"},
+		"replica.host":     {"replicaDatabase", "host"},
+		"replica-pool-max": {"replicaDatabase", "pool", "max"},
+		"primary-pool.max": {"primary", "pool", "max"},
+		"replica-":         {"replica-"},
+		"primary.host":     {"primary", "host"},
+		"debug-mode":       {"debug-mode"},
+	}
+	for key, expected := range argTests {
+		if result := table.argPath(key); !reflect.DeepEqual(result, expected) {
+			t.Errorf("argPath(%q) = %v, expected %v", key, result, expected)
+		}
+	}
+}
+
+func TestLoad_DuplicatePrefix(t *testing.T) {
+	type EnvConflictConfig struct {
+		Primary DBConfig `json:"primary" prefix:"db"`
+		Replica DBConfig `json:"replica" prefix:"DB"`
+	}
+	type ArgConflictConfig struct {
+		Primary DBConfig `json:"primary" prefix:"db-pool"`
+		Replica DBConfig `json:"replica" prefix:"db"`
+	}
+
+	_, err := New[EnvConflictConfig]().WithEnvVars([]string{}).Load()
+	expected := `invalid config schema: fields "primary" and "replica" share the environment variable prefix "db"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+
+	_, err = New[ArgConflictConfig]().WithEnvVars([]string{}).Load()
+	expected = `invalid config schema: fields "primary" and "replica.pool" share the argument prefix "db-pool"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+}
+
+func TestLoad_Prefix(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	baseData := []byte(`{
+		"primary": {"host": "db-primary", "port": 5432},
+		"replicaDatabase": {"host": "db-replica", "port": 5432, "pool": {"max": 10}}
+	}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	appSettings := New[PrefixConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvVars([]string{"REPLICA__HOST=env-replica", "PRIMARY__POOL__MAX=20"}).
+		WithArgs([]string{"program", "--replica-port", "6432", "--replica-pool-max", "30", "--cache.bak-host", "backup"})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := &PrefixConfig{
+		Primary: DBConfig{Host: "db-primary", Port: 5432, Pool: DBPoolConfig{Max: 20}},
+		Replica: &DBConfig{Host: "env-replica", Port: 6432, Pool: DBPoolConfig{Max: 30}},
+	}
+	expected.Cache.Backup = DBConfig{Host: "backup"}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected config %+v, got %+v", expected, result)
+	}
+}
@@ -0,0 +1,117 @@
+package appsettings
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// decodePropertiesConfig decodes a Java .properties document. Dotted keys (e.g., database.pool.max or
+// upstreams.0.host) map to nested paths like command line arguments, and values are kept as strings and
+// converted for the type of their field when loaded. Keys and values are separated by =, : or whitespace; lines ending with
+// an odd number of backslashes continue on the next line, and lines starting with # or ! are comments.
+func decodePropertiesConfig(data []byte) (map[string]interface{}, error) {
+	config := make(map[string]interface{})
+	lines := strings.Split(strings.ReplaceAll(strings.TrimPrefix(string(data), "\ufeff"), "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		number := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		// Join continuation lines, dropping the leading whitespace of each continued line
+		for isPropertiesContinued(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+
+		key, value, err := splitProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("properties: line %d: %w", number, err)
+		}
+		if err := setPath(config, splitKeyPath(key, argPathSeparator), value); err != nil {
+			return nil, fmt.Errorf("properties: line %d: %w", number, err)
+		}
+	}
+
+	return config, nil
+}
+
+// isPropertiesContinued reports whether line ends with an unescaped backslash.
+func isPropertiesContinued(line string) bool {
+	backslashes := len(line) - len(strings.TrimRight(line, "\\"))
+	return backslashes%2 == 1
+}
+
+// splitProperty splits a logical line into its unescaped key and value.
+func splitProperty(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	// The separator is surrounded by optional whitespace and may itself be whitespace only
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	key, err := unescapeProperty(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+	value, err := unescapeProperty(rest)
+	if err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+// unescapeProperty resolves the escape sequences (e.g., \t, \= or \u00e9) of a key or value.
+func unescapeProperty(text string) (string, error) {
+	if !strings.Contains(text, "\\") {
+		return text, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 == len(text) {
+			b.WriteByte(text[i])
+			continue
+		}
+
+		i++
+		switch text[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(text) {
+				return "", fmt.Errorf("invalid escape sequence \\%s", text[i:])
+			}
+			code, err := strconv.ParseUint(text[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape sequence \\%s", text[i:i+5])
+			}
+			b.WriteRune(rune(code))
+			i += 4
+		default:
+			b.WriteByte(text[i])
+		}
+	}
+	return b.String(), nil
+}
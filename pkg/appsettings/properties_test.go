@@ -0,0 +1,110 @@
+package appsettings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDecodePropertiesConfig(t *testing.T) {
+	data := []byte(`# Application settings
+! also a comment
+name=my-app
+port : 8080
+debug true
+database.host = localhost
+database.pool.max=10
+upstreams.0.host=a
+upstreams.1.host=b
+message = Hello, \
+          World
+path = C:\\app\\data
+escaped\ key = tab\there \u00e9
+empty
+`)
+
+	result, err := decodePropertiesConfig(data)
+	if err != nil {
+		t.Fatalf("decodePropertiesConfig() returned error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"name":  "my-app",
+		"port":  "8080",
+		"debug": "true",
+		"database": map[string]interface{}{
+			"host": "localhost",
+			"pool": map[string]interface{}{"max": "10"},
+		},
+		"upstreams": []interface{}{
+			map[string]interface{}{"host": "a"},
+			map[string]interface{}{"host": "b"},
+		},
+		"message":     "Hello, World",
+		"path":        `C:\app\data`,
+		"escaped key": "tab\there é",
+		"empty":       "",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestDecodePropertiesConfig_Errors(t *testing.T) {
+	tests := map[string]string{
+		`name=\u12`:         `properties: line 1: invalid escape sequence \u12`,
+		"a=1\nname=\\uZZZZ": `properties: line 2: invalid escape sequence \uZZZZ`,
+	}
+
+	for data, expected := range tests {
+		_, err := decodePropertiesConfig([]byte(data))
+		if err == nil || err.Error() != expected {
+			t.Errorf("decodePropertiesConfig(%q) = %v, expected %q", data, err, expected)
+		}
+	}
+}
+
+func TestLoad_PropertiesConfigFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.properties")
+	if err := os.WriteFile(baseConfigFile, []byte("databaseURL=postgres://localhost/properties\nport=8080\n"), 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	result, err := New[TestConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvVars([]string{"PORT=9090"}).
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.DatabaseURL != "postgres://localhost/properties" || result.Port != 9090 {
+		t.Errorf("Expected values from properties file overridden by env vars, got %+v", *result)
+	}
+}
+
+func TestLoad_PropertiesConfigFileTypedValues(t *testing.T) {
+	type Config struct {
+		Retries  int    `json:"retries"`
+		Enabled  bool   `json:"enabled"`
+		Password string `json:"password"`
+	}
+
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "config.properties"), []byte("retries=1\nenabled=1\npassword=123456\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	result, err := New[Config]().WithConfigDirectory(tempDir).Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := Config{Retries: 1, Enabled: true, Password: "123456"}
+	if *result != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *result)
+	}
+}
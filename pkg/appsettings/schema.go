@@ -0,0 +1,147 @@
+package appsettings
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// unionDefinition holds the arguments of a WithUnion call until the schema is built.
+type unionDefinition struct {
+	iface         any
+	discriminator string
+	variants      map[string]any
+}
+
+// union describes a discriminated union: an interface type whose concrete type is selected
+// by the value of a discriminator key in the config object.
+type union struct {
+	discriminator string
+	variants      map[string]reflect.Type
+}
+
+// schema holds the type information used to resolve, canonicalize and decode config values.
+type schema struct {
+	unions   map[reflect.Type]*union
+	prefixes *prefixTable
+	// lenientScalars enables the lenient scalar grammar for string values of bool and numeric fields.
+	lenientScalars bool
+}
+
+// newSchema creates a schema for config type t and the given union definitions.
+func newSchema(t reflect.Type, definitions []unionDefinition) (*schema, error) {
+	prefixes, err := newPrefixTable(t)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &schema{
+		unions:   make(map[reflect.Type]*union, len(definitions)),
+		prefixes: prefixes,
+	}
+	for _, definition := range definitions {
+		ifaceType, u, err := newUnion(definition.iface, definition.discriminator, definition.variants)
+		if err != nil {
+			return nil, err
+		}
+		s.unions[ifaceType] = u
+	}
+	return s, nil
+}
+
+// newUnion creates a union for the interface type of iface, given as a nil pointer to the interface
+// (e.g., (*Storage)(nil)). Each variant is given as a value of its concrete type; if only the pointer
+// type implements the interface, the pointer type is used.
+func newUnion(iface any, discriminator string, variants map[string]any) (reflect.Type, *union, error) {
+	ifaceType := reflect.TypeOf(iface)
+	if ifaceType == nil || ifaceType.Kind() != reflect.Pointer || ifaceType.Elem().Kind() != reflect.Interface {
+		return nil, nil, fmt.Errorf("union type must be a nil pointer to an interface, got %T", iface)
+	}
+	ifaceType = ifaceType.Elem()
+
+	if discriminator == "" {
+		return nil, nil, fmt.Errorf("union %s has an empty discriminator", ifaceType)
+	}
+
+	result := &union{discriminator: discriminator, variants: make(map[string]reflect.Type, len(variants))}
+	for value, variant := range variants {
+		variantType := reflect.TypeOf(variant)
+		switch {
+		case variantType == nil:
+			return nil, nil, fmt.Errorf("variant %q of union %s is nil", value, ifaceType)
+		case variantType.Implements(ifaceType):
+		case reflect.PointerTo(variantType).Implements(ifaceType):
+			variantType = reflect.PointerTo(variantType)
+		default:
+			return nil, nil, fmt.Errorf("variant %q of union %s: %s does not implement it", value, ifaceType, variantType)
+		}
+		result.variants[value] = variantType
+	}
+
+	return ifaceType, result, nil
+}
+
+// variantType returns the concrete type selected by the discriminator in object for the union type t.
+// It returns nil without an error if t is not a registered union.
+func (s *schema) variantType(t reflect.Type, object map[string]interface{}) (reflect.Type, error) {
+	u, ok := s.unions[t]
+	if !ok {
+		return nil, nil
+	}
+
+	raw, ok := object[u.discriminator]
+	if !ok {
+		for key, value := range object {
+			if strings.EqualFold(key, u.discriminator) {
+				raw = value
+				break
+			}
+		}
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("missing discriminator %q for %s", u.discriminator, t)
+	}
+
+	value, _ := scalarText(raw)
+	variant, ok := u.variants[value]
+	if !ok {
+		known := make([]string, 0, len(u.variants))
+		for name := range u.variants {
+			known = append(known, name)
+		}
+		slices.Sort(known)
+		return nil, fmt.Errorf("unknown %s %q for %s, expected one of %s",
+			u.discriminator, value, t, strings.Join(known, ", "))
+	}
+
+	return variant, nil
+}
+
+// discriminatorKey returns the discriminator key if t is a union and key matches it case-insensitively.
+func (s *schema) discriminatorKey(t reflect.Type, key string) (string, bool) {
+	if u, ok := s.unions[t]; ok && strings.EqualFold(key, u.discriminator) {
+		return u.discriminator, true
+	}
+	return "", false
+}
+
+// selectVariant returns the concrete type of the union type t for the config value at path, using
+// the discriminator of raw if present and of the value at path in merged otherwise. It returns t
+// unchanged if t is not a union or no variant can be selected.
+func (s *schema) selectVariant(t reflect.Type, raw interface{}, merged map[string]interface{}, path []string) reflect.Type {
+	if t.Kind() != reflect.Interface {
+		return t
+	}
+
+	for _, candidate := range []interface{}{raw, lookupPath(merged, path)} {
+		object, ok := candidate.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if variant, err := s.variantType(t, object); err == nil && variant != nil {
+			return variant
+		}
+	}
+	return t
+}
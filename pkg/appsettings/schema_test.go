@@ -0,0 +1,177 @@
+package appsettings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Storage interface {
+	Describe() string
+}
+
+type S3Storage struct {
+	Type   string `json:"type"`
+	Bucket string `json:"bucket"`
+	Region string `json:"region"`
+}
+
+func (s S3Storage) Describe() string { return "s3://" + s.Bucket }
+
+type LocalStorage struct {
+	Path     string `json:"path"`
+	MaxFiles int    `json:"maxFiles"`
+}
+
+func (l *LocalStorage) Describe() string { return "file://" + l.Path }
+
+type StorageConfig struct {
+	Storage  Storage   `json:"storage"`
+	Replicas []Storage `json:"replicas"`
+	Name     string    `json:"name"`
+}
+
+func newStorageSettings() *AppSettings[StorageConfig] {
+	return New[StorageConfig]().
+		WithUnion((*Storage)(nil), "type", map[string]any{
+			"s3":    S3Storage{},
+			"local": LocalStorage{},
+		})
+}
+
+func TestLoad_Union(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	baseData := []byte(`{
+		"storage": {"type": "local", "path": "/data", "maxFiles": 10},
+		"replicas": [{"type": "s3", "bucket": "backup"}, {"Type": "local", "Path": "/mnt"}]
+	}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	envConfigFile := filepath.Join(tempDir, "config.prod.json")
+	envData := []byte(`{"storage": {"type": "s3", "bucket": "prod-bucket"}}`)
+	if err := os.WriteFile(envConfigFile, envData, 0600); err != nil {
+		t.Fatalf("Failed to write env config: %v", err)
+	}
+
+	appSettings := newStorageSettings().
+		WithConfigDirectory(tempDir).
+		WithEnvironment("prod").
+		WithEnvVars([]string{"STORAGE__REGION=eu-west-1", "REPLICAS__1__MAXFILES=5"}).
+		WithArgs([]string{"program", "--replicas.0.region", "us-east-1"})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := &StorageConfig{
+		Storage: S3Storage{Type: "s3", Bucket: "prod-bucket", Region: "eu-west-1"},
+		Replicas: []Storage{
+			S3Storage{Type: "s3", Bucket: "backup", Region: "us-east-1"},
+			&LocalStorage{Path: "/mnt", MaxFiles: 5},
+		},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected config %+v, got %+v", expected, result)
+	}
+}
+
+func TestLoad_UnionDiscriminatorFromEnv(t *testing.T) {
+	appSettings := newStorageSettings().
+		WithConfigDirectory(t.TempDir()).
+		WithEnvVars([]string{"STORAGE__TYPE=local", "STORAGE__PATH=/var/data", "STORAGE__MAXFILES=3"})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := &LocalStorage{Path: "/var/data", MaxFiles: 3}
+	if !reflect.DeepEqual(result.Storage, expected) {
+		t.Errorf("Expected storage %+v, got %+v", expected, result.Storage)
+	}
+}
+
+func TestLoad_UnionErrors(t *testing.T) {
+	tests := []struct {
+		envVars  []string
+		expected string
+	}{
+		{[]string{"STORAGE__BUCKET=b"}, `storage: missing discriminator "type"`},
+		{[]string{"STORAGE__TYPE=gcs"}, `storage: unknown type "gcs" for appsettings.Storage, expected one of local, s3`},
+		{[]string{"STORAGE=s3"}, "storage: cannot decode string into appsettings.Storage"},
+	}
+
+	for _, test := range tests {
+		appSettings := newStorageSettings().
+			WithConfigDirectory(t.TempDir()).
+			WithEnvVars(test.envVars)
+
+		_, err := appSettings.Load()
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("Load() with %v returned error %v, expected %q", test.envVars, err, test.expected)
+		}
+	}
+}
+
+func TestLoad_UnionWithoutRegistration(t *testing.T) {
+	appSettings := New[StorageConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithEnvVars([]string{"STORAGE__TYPE=s3"})
+
+	if _, err := appSettings.Load(); err == nil {
+		t.Error("Load() should return error for an unregistered interface type")
+	}
+}
+
+func TestNewSchema_InvalidUnions(t *testing.T) {
+	tests := []struct {
+		definition unionDefinition
+		expected   string
+	}{
+		{unionDefinition{iface: Storage(nil), discriminator: "type"}, "nil pointer to an interface"},
+		{unionDefinition{iface: (*S3Storage)(nil), discriminator: "type"}, "nil pointer to an interface"},
+		{unionDefinition{iface: (*Storage)(nil), discriminator: ""}, "empty discriminator"},
+		{unionDefinition{iface: (*Storage)(nil), discriminator: "type", variants: map[string]any{"x": nil}}, "is nil"},
+		{unionDefinition{iface: (*Storage)(nil), discriminator: "type", variants: map[string]any{"x": 1}}, "int does not implement"},
+	}
+
+	for _, test := range tests {
+		_, err := newSchema(reflect.TypeFor[StorageConfig](), []unionDefinition{test.definition})
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("newSchema(%+v) returned error %v, expected %q", test.definition, err, test.expected)
+		}
+	}
+
+	appSettings := New[StorageConfig]().WithUnion((*S3Storage)(nil), "type", nil)
+	if _, err := appSettings.Load(); err == nil {
+		t.Error("Load() should return error for an invalid union")
+	}
+}
+
+func TestWithUnion(t *testing.T) {
+	appSettings := New[StorageConfig]()
+
+	result := appSettings.WithUnion((*Storage)(nil), "type", map[string]any{"s3": S3Storage{}})
+
+	if result != appSettings {
+		t.Error("WithUnion should return the same instance for chaining")
+	}
+
+	s, err := appSettings.schema()
+	if err != nil {
+		t.Fatalf("schema() returned error: %v", err)
+	}
+
+	u, ok := s.unions[reflect.TypeFor[Storage]()]
+	if !ok || u.discriminator != "type" || u.variants["s3"] != reflect.TypeFor[S3Storage]() {
+		t.Errorf("Expected union for Storage with variant s3, got %+v", s.unions)
+	}
+}
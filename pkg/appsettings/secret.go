@@ -0,0 +1,73 @@
+package appsettings
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+)
+
+// secretMask is printed, logged and marshalled in place of a secret value.
+const secretMask = "******"
+
+// Secret wraps a sensitive config value (e.g., a password or API key) so that it never leaks
+// through fmt, encoding/json or log/slog. It loads from every config source like the wrapped
+// type T, and the real value is only available through Reveal.
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret creates a Secret holding value.
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Reveal returns the wrapped secret value.
+func (s Secret[T]) Reveal() T {
+	return s.value
+}
+
+// String implements fmt.Stringer and returns a mask instead of the secret value.
+func (s Secret[T]) String() string {
+	return secretMask
+}
+
+// GoString implements fmt.GoStringer and returns a mask instead of the secret value.
+func (s Secret[T]) GoString() string {
+	return secretMask
+}
+
+// Format implements fmt.Formatter so that the mask is printed for every verb.
+func (s Secret[T]) Format(f fmt.State, _ rune) {
+	_, _ = io.WriteString(f, secretMask)
+}
+
+// LogValue implements slog.LogValuer and returns a mask instead of the secret value.
+func (s Secret[T]) LogValue() slog.Value {
+	return slog.StringValue(secretMask)
+}
+
+// MarshalJSON implements json.Marshaler and returns a mask instead of the secret value.
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(secretMask)
+}
+
+// UnmarshalJSON implements json.Unmarshaler and decodes data into the wrapped value.
+func (s *Secret[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &s.value)
+}
+
+// wrappedType returns the type of the wrapped value.
+func (s Secret[T]) wrappedType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// decodeRaw decodes a raw config value into the wrapped value.
+// Decoding errors are redacted since they may quote the secret value.
+func (s *Secret[T]) decodeRaw(sch *schema, raw interface{}, path string) error {
+	if err := sch.decodeValue(raw, reflect.ValueOf(&s.value).Elem(), path); err != nil {
+		return decodeError(path, fmt.Errorf("cannot decode secret into %s", reflect.TypeFor[T]()))
+	}
+	return nil
+}
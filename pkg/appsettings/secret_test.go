@@ -0,0 +1,132 @@
+package appsettings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type SecretConfig struct {
+	Password Secret[string]        `json:"password"`
+	APIKey   Secret[string]        `json:"apiKey"`
+	PIN      Secret[int]           `json:"pin"`
+	Rotation Secret[time.Duration] `json:"rotation"`
+	Token    *Secret[string]       `json:"token"`
+	User     string                `json:"user"`
+}
+
+func TestLoad_Secret(t *testing.T) {
+	tempDir := t.TempDir()
+
+	baseConfigFile := filepath.Join(tempDir, "config.json")
+	baseData := []byte(`{"password": "file-password", "pin": 1234, "user": "admin"}`)
+	if err := os.WriteFile(baseConfigFile, baseData, 0600); err != nil {
+		t.Fatalf("Failed to write base config: %v", err)
+	}
+
+	appSettings := New[SecretConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvVars([]string{"APIKEY=env-key", "ROTATION=24h"}).
+		WithArgs([]string{"program", "--token", "arg-token"})
+
+	result, err := appSettings.Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.Password.Reveal() != "file-password" {
+		t.Errorf("Expected password 'file-password', got %q", result.Password.Reveal())
+	}
+
+	if result.APIKey.Reveal() != "env-key" {
+		t.Errorf("Expected api key 'env-key', got %q", result.APIKey.Reveal())
+	}
+
+	if result.PIN.Reveal() != 1234 {
+		t.Errorf("Expected pin 1234, got %d", result.PIN.Reveal())
+	}
+
+	if result.Rotation.Reveal() != 24*time.Hour {
+		t.Errorf("Expected rotation 24h, got %v", result.Rotation.Reveal())
+	}
+
+	if result.Token == nil || result.Token.Reveal() != "arg-token" {
+		t.Errorf("Expected token 'arg-token', got %v", result.Token)
+	}
+}
+
+func TestLoad_SecretDecodeErrorRedacted(t *testing.T) {
+	appSettings := New[SecretConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithEnvVars([]string{"PIN=12ab34"})
+
+	_, err := appSettings.Load()
+	if err == nil {
+		t.Fatal("Load() should return error for invalid secret value")
+	}
+
+	if strings.Contains(err.Error(), "12ab34") {
+		t.Errorf("Error should not contain the secret value, got: %v", err)
+	}
+}
+
+func TestSecret_NeverLeaks(t *testing.T) {
+	token := NewSecret("token-value")
+	config := SecretConfig{
+		Password: NewSecret("password-value"),
+		PIN:      NewSecret(987654),
+		Token:    &token,
+		User:     "admin",
+	}
+
+	outputs := []string{
+		fmt.Sprintf("%v", config),
+		fmt.Sprintf("%+v", config),
+		fmt.Sprintf("%#v", config),
+		fmt.Sprintf("%s", config.Password),
+		fmt.Sprintf("%d", config.PIN),
+		fmt.Sprintf("%q", config.Password),
+		fmt.Sprint(config.Password),
+		config.Password.String(),
+		config.Password.GoString(),
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	outputs = append(outputs, string(data))
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	logger.Info("config", "password", config.Password, "pin", config.PIN)
+	outputs = append(outputs, logs.String())
+
+	for _, output := range outputs {
+		for _, secret := range []string{"password-value", "987654", "token-value"} {
+			if strings.Contains(output, secret) {
+				t.Errorf("Output leaks secret %q: %s", secret, output)
+			}
+		}
+		if !strings.Contains(output, secretMask) {
+			t.Errorf("Output should contain mask %q: %s", secretMask, output)
+		}
+	}
+}
+
+func TestSecret_UnmarshalJSON(t *testing.T) {
+	var secret Secret[string]
+	if err := json.Unmarshal([]byte(`"value"`), &secret); err != nil {
+		t.Fatalf("json.Unmarshal() returned error: %v", err)
+	}
+
+	if secret.Reveal() != "value" {
+		t.Errorf("Expected secret 'value', got %q", secret.Reveal())
+	}
+}
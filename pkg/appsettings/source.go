@@ -0,0 +1,251 @@
+package appsettings
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+)
+
+// Default priorities of the built-in layers. Layers are applied in ascending priority, so layers with a higher
+// priority override those with a lower one. They are spaced apart so that sources added with WithSource
+// can be placed between any two built-in layers.
+const (
+	PriorityBaseConfig   = 100
+	PriorityEnvConfig    = 200
+	PriorityFiles        = 250
+	PriorityDotEnv       = 300
+	PriorityConfigEnvVar = 400
+	PriorityEnvVars      = 500
+	PriorityArgs         = 600
+)
+
+// Layer identifies a built-in layer of the load pipeline, whose priority can be changed with WithLayerPriority
+// and which can be disabled with WithoutLayer.
+type Layer int
+
+const (
+	// LayerBaseConfig is the layer of the base config files (e.g., config.json).
+	LayerBaseConfig Layer = iota + 1
+	// LayerEnvConfig is the layer of the environment-specific config files (e.g., config.dev.json).
+	LayerEnvConfig
+	// LayerFiles is the layer of the config files added with WithFile.
+	LayerFiles
+	// LayerDotEnv is the layer of the .env files enabled with WithDotEnv.
+	LayerDotEnv
+	// LayerConfigEnvVar is the layer of the whole-config environment variable set with WithConfigEnvVar.
+	LayerConfigEnvVar
+	// LayerEnvVars is the layer of the environment variables set with WithEnvVars.
+	LayerEnvVars
+	// LayerArgs is the layer of the command line arguments set with WithArgs.
+	LayerArgs
+)
+
+// defaultPriority returns the priority of the layer unless changed with WithLayerPriority.
+func (l Layer) defaultPriority() (int, bool) {
+	switch l {
+	case LayerBaseConfig:
+		return PriorityBaseConfig, true
+	case LayerEnvConfig:
+		return PriorityEnvConfig, true
+	case LayerFiles:
+		return PriorityFiles, true
+	case LayerDotEnv:
+		return PriorityDotEnv, true
+	case LayerConfigEnvVar:
+		return PriorityConfigEnvVar, true
+	case LayerEnvVars:
+		return PriorityEnvVars, true
+	case LayerArgs:
+		return PriorityArgs, true
+	default:
+		return 0, false
+	}
+}
+
+// Source is a custom config source, such as a remote config service or a secrets manager.
+type Source interface {
+	// Name identifies the source in errors and warnings.
+	Name() string
+	// Load returns the config values of the source as nested maps keyed like the config type. Values must have
+	// the types produced by the built-in formats: nil, bool, string, int, float64, json.Number, []any and map[string]any.
+	Load(ctx context.Context) (map[string]any, error)
+}
+
+// prioritizedSource is a source added with WithSource.
+type prioritizedSource struct {
+	source   Source
+	priority int
+}
+
+// layer is a step of the load pipeline, overlaying the values of one source into the config map.
+type layer struct {
+	// builtin is the built-in layer, or zero for a custom source.
+	builtin  Layer
+	priority int
+	load     func(ctx context.Context, configMap map[string]interface{}) error
+}
+
+// WithSource adds a custom config source applied at the given priority. Built-in layers have the priorities
+// PriorityBaseConfig through PriorityArgs; for example, a priority of 450 applies the source after the
+// whole-config env var and before individual environment variables. Sources with the same priority as a
+// built-in layer are applied after it, and sources with the same priority in the order they were added.
+func (a *AppSettings[T]) WithSource(s Source, priority int) *AppSettings[T] {
+	a.withSources = append(a.withSources, prioritizedSource{source: s, priority: priority})
+	return a
+}
+
+// WithLayerPriority changes the priority of a built-in layer, e.g. to let config files override environment
+// variables injected by the platform:
+//
+//	WithLayerPriority(appsettings.LayerEnvVars, appsettings.PriorityBaseConfig-50)
+//
+// Built-in layers with equal priorities are applied in their default order. Unknown layers make Load fail.
+func (a *AppSettings[T]) WithLayerPriority(layer Layer, priority int) *AppSettings[T] {
+	if a.withLayerPriorities == nil {
+		a.withLayerPriorities = make(map[Layer]int)
+	}
+	a.withLayerPriorities[layer] = priority
+	return a
+}
+
+// WithoutLayer disables a built-in layer, e.g. to replace it with a custom source that loads the same
+// values differently:
+//
+//	WithoutLayer(appsettings.LayerEnvVars).
+//	WithSource(filteredEnvSource{}, appsettings.PriorityEnvVars)
+//
+// Unknown layers make Load fail.
+func (a *AppSettings[T]) WithoutLayer(layer Layer) *AppSettings[T] {
+	if a.withoutLayers == nil {
+		a.withoutLayers = make(map[Layer]bool)
+	}
+	a.withoutLayers[layer] = true
+	return a
+}
+
+// layers returns the enabled built-in layers and custom sources in the order they are applied.
+func (a *AppSettings[T]) layers(configDir string) ([]layer, error) {
+	configured := slices.Concat(slices.Collect(maps.Keys(a.withLayerPriorities)), slices.Collect(maps.Keys(a.withoutLayers)))
+	for _, l := range configured {
+		if _, ok := l.defaultPriority(); !ok {
+			return nil, fmt.Errorf("unknown layer %d", l)
+		}
+	}
+
+	layers := []layer{
+		{LayerBaseConfig, 0, func(_ context.Context, configMap map[string]interface{}) error {
+			baseConfigPaths := configFilePaths(configDir, "")
+			if a.withRequiredConfigFiles {
+				if err := requireConfigFile(baseConfigPaths); err != nil {
+					return fmt.Errorf("failed to load base config: %w", err)
+				}
+			}
+			for _, baseConfigPath := range baseConfigPaths {
+				if err := a.loadConfigFile(baseConfigPath, configMap); err != nil {
+					return fmt.Errorf("failed to load base config: %w", err)
+				}
+			}
+			return nil
+		}},
+		{LayerEnvConfig, 0, func(_ context.Context, configMap map[string]interface{}) error {
+			if a.withEnvironment == nil {
+				return nil
+			}
+			envConfigPaths := configFilePaths(configDir, *a.withEnvironment)
+			if a.withRequiredConfigFiles {
+				if err := requireConfigFile(envConfigPaths); err != nil {
+					return fmt.Errorf("failed to load env config: %w", err)
+				}
+			}
+			for _, envConfigPath := range envConfigPaths {
+				if err := a.loadConfigFile(envConfigPath, configMap); err != nil {
+					return fmt.Errorf("failed to load env config: %w", err)
+				}
+			}
+			return nil
+		}},
+		{LayerFiles, 0, func(_ context.Context, configMap map[string]interface{}) error {
+			return a.loadFiles(configDir, configMap)
+		}},
+		{LayerDotEnv, 0, func(_ context.Context, configMap map[string]interface{}) error {
+			if err := a.loadDotEnv(configDir, configMap); err != nil {
+				return fmt.Errorf("failed to load dotenv: %w", err)
+			}
+			return nil
+		}},
+		{LayerConfigEnvVar, 0, func(_ context.Context, configMap map[string]interface{}) error {
+			if err := a.loadConfigEnvVar(configMap); err != nil {
+				return fmt.Errorf("failed to load config env var: %w", err)
+			}
+			return nil
+		}},
+		{LayerEnvVars, 0, func(_ context.Context, configMap map[string]interface{}) error {
+			if err := a.loadEnvVars(configMap); err != nil {
+				return fmt.Errorf("failed to load env vars: %w", err)
+			}
+			return nil
+		}},
+		{LayerArgs, 0, func(_ context.Context, configMap map[string]interface{}) error {
+			if err := a.loadArgs(configMap); err != nil {
+				return fmt.Errorf("failed to load args: %w", err)
+			}
+			return nil
+		}},
+	}
+
+	layers = slices.DeleteFunc(layers, func(l layer) bool { return a.withoutLayers[l.builtin] })
+	for i := range layers {
+		layers[i].priority = a.layerPriority(layers[i].builtin)
+	}
+
+	for _, source := range a.withSources {
+		layers = append(layers, layer{0, source.priority, func(ctx context.Context, configMap map[string]interface{}) error {
+			if err := a.loadSource(ctx, source.source, configMap); err != nil {
+				return fmt.Errorf("failed to load source %q: %w", source.source.Name(), err)
+			}
+			return nil
+		}})
+	}
+
+	slices.SortStableFunc(layers, func(x, y layer) int { return cmp.Compare(x.priority, y.priority) })
+	return layers, nil
+}
+
+// layerPriority returns the priority of a built-in layer, as changed with WithLayerPriority or its default.
+func (a *AppSettings[T]) layerPriority(l Layer) int {
+	if priority, ok := a.withLayerPriorities[l]; ok {
+		return priority
+	}
+	priority, _ := l.defaultPriority()
+	return priority
+}
+
+// loadSource overlays the values of a custom source into configMap.
+func (a *AppSettings[T]) loadSource(ctx context.Context, source Source, configMap map[string]interface{}) error {
+	values, err := source.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	s, err := a.schema()
+	if err != nil {
+		return err
+	}
+
+	// Relative paths are resolved against the working directory like for environment variables
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	// Copy the values, as canonicalizing and merging modify them in place
+	sourceConfig, _ := cloneValue(values).(map[string]interface{})
+	sourceConfig, _ = a.canonicalize(s, sourceConfig, reflect.TypeFor[T](), nil, configMap, source.Name(), cwd).(map[string]interface{})
+	mergeMaps(configMap, sourceConfig)
+
+	return nil
+}
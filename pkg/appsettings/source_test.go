@@ -0,0 +1,216 @@
+package appsettings
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mapSource is a Source returning fixed values.
+type mapSource struct {
+	name   string
+	values map[string]any
+	err    error
+}
+
+func (s mapSource) Name() string { return s.name }
+
+func (s mapSource) Load(context.Context) (map[string]any, error) { return s.values, s.err }
+
+func TestLoad_WithSource(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "config.json"), []byte(`{"name": "file", "port": 8000}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	remote := mapSource{name: "remote", values: map[string]any{"NAME": "remote", "port": 8001, "debugMode": true}}
+	result, err := New[TestConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvVars([]string{"PORT=9090"}).
+		WithSource(remote, PriorityConfigEnvVar+50).
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.Name != "remote" || !result.DebugMode {
+		t.Errorf("Expected source to override config file, got %+v", *result)
+	}
+	if result.Port != 9090 {
+		t.Errorf("Expected env vars to override source, got port %d", result.Port)
+	}
+	if remote.values["NAME"] != "remote" {
+		t.Errorf("Expected source values to be left unchanged, got %v", remote.values)
+	}
+}
+
+func TestLoad_WithSourcePriorityOrder(t *testing.T) {
+	result, err := New[TestConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithArgs([]string{"--name", "args"}).
+		WithSource(mapSource{name: "second", values: map[string]any{"name": "second"}}, PriorityArgs).
+		WithSource(mapSource{name: "first", values: map[string]any{"name": "first", "port": 1}}, PriorityArgs).
+		WithSource(mapSource{name: "low", values: map[string]any{"name": "low", "port": 2}}, 0).
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.Name != "first" || result.Port != 1 {
+		t.Errorf("Expected sources of equal priority to apply after args in the order added, got %+v", *result)
+	}
+}
+
+func TestLoad_WithSourceError(t *testing.T) {
+	errUnavailable := errors.New("service unavailable")
+	_, err := New[TestConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithSource(mapSource{name: "remote", err: errUnavailable}, PriorityEnvVars).
+		Load()
+
+	if !errors.Is(err, errUnavailable) || !strings.Contains(err.Error(), `failed to load source "remote"`) {
+		t.Errorf("Expected source error, got %v", err)
+	}
+}
+
+// blockingSource is a Source waiting until its context is done.
+type blockingSource struct{}
+
+func (blockingSource) Name() string { return "blocking" }
+
+func (blockingSource) Load(ctx context.Context) (map[string]any, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestLoadContext_Cancellation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := New[TestConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithSource(blockingSource{}, PriorityEnvVars).
+		LoadContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), `failed to load source "blocking"`) {
+		t.Errorf("Expected deadline exceeded error from source, got %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	config := TestConfig{Name: "default"}
+	err = New[TestConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithEnvVars([]string{"NAME=env"}).
+		LoadIntoContext(cancelled, &config)
+	if !errors.Is(err, context.Canceled) || config.Name != "default" {
+		t.Errorf("Expected cancelled context to stop loading, got %v with %+v", err, config)
+	}
+}
+
+func TestLoad_WithLayerPriority(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"config.json":     `{"name": "base", "port": 8000}`,
+		"config.dev.json": `{"port": 8001}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	result, err := New[TestConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvironment("dev").
+		WithEnvVars([]string{"NAME=env", "PORT=9090", "DEBUGMODE=true"}).
+		WithLayerPriority(LayerEnvVars, PriorityBaseConfig-50).
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.Name != "base" || result.Port != 8001 {
+		t.Errorf("Expected config files to override env vars, got %+v", *result)
+	}
+	if !result.DebugMode {
+		t.Error("Expected env vars to still apply where config files are silent")
+	}
+}
+
+func TestLoad_WithLayerPriorityTies(t *testing.T) {
+	result, err := New[TestConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithEnvVars([]string{"NAME=env"}).
+		WithArgs([]string{"--name", "args"}).
+		WithLayerPriority(LayerArgs, PriorityEnvVars).
+		WithLayerPriority(LayerEnvVars, PriorityEnvVars).
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.Name != "args" {
+		t.Errorf("Expected layers with equal priorities to apply in default order, got %q", result.Name)
+	}
+}
+
+func TestLoad_WithLayerPriorityExtremes(t *testing.T) {
+	result, err := New[TestConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithEnvVars([]string{"NAME=env", "PORT=9090"}).
+		WithArgs([]string{"--name", "args"}).
+		WithLayerPriority(LayerEnvVars, math.MaxInt).
+		WithLayerPriority(LayerArgs, math.MinInt).
+		WithSource(mapSource{name: "remote", values: map[string]any{"name": "remote", "port": 1}}, math.MaxInt-1).
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.Name != "env" || result.Port != 9090 {
+		t.Errorf("Expected env vars at the highest priority to override all layers, got %+v", *result)
+	}
+}
+
+func TestLoad_WithLayerPriorityUnknownLayer(t *testing.T) {
+	_, err := New[TestConfig]().
+		WithConfigDirectory(t.TempDir()).
+		WithLayerPriority(Layer(42), 0).
+		Load()
+
+	if err == nil || err.Error() != "invalid layers: unknown layer 42" {
+		t.Errorf("Expected unknown layer error, got %v", err)
+	}
+}
+
+func TestLoad_WithoutLayer(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "config.json"), []byte(`{"name": "base", "port": 8000}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	result, err := New[TestConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvVars([]string{"NAME=env", "PORT=9090"}).
+		WithoutLayer(LayerEnvVars).
+		WithSource(mapSource{name: "env", values: map[string]any{"name": "replaced"}}, PriorityEnvVars).
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.Name != "replaced" || result.Port != 8000 {
+		t.Errorf("Expected the source to replace the env vars layer, got %+v", *result)
+	}
+
+	_, err = New[TestConfig]().WithConfigDirectory(tempDir).WithoutLayer(Layer(0)).Load()
+	if err == nil || err.Error() != "invalid layers: unknown layer 0" {
+		t.Errorf("Expected unknown layer error, got %v", err)
+	}
+}
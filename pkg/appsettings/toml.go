@@ -0,0 +1,605 @@
+package appsettings
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+//nolint:gochecknoglobals // immutable patterns of the TOML value grammar
+var (
+	tomlBareKeyPattern  = regexp.MustCompile(`^[A-Za-z0-9_-]+`)
+	tomlDecimalPattern  = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)$`)
+	tomlHexPattern      = regexp.MustCompile(`^0x[0-9A-Fa-f](_?[0-9A-Fa-f])*$`)
+	tomlOctalPattern    = regexp.MustCompile(`^0o[0-7](_?[0-7])*$`)
+	tomlBinaryPattern   = regexp.MustCompile(`^0b[01](_?[01])*$`)
+	tomlFloatPattern    = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][+-]?[0-9](_?[0-9])*)?$`)
+	tomlDateTimePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})?([Tt ]?)(\d{2}:\d{2}:\d{2}(\.\d+)?)?([Zz]|[+-]\d{2}:\d{2})?$`)
+)
+
+// tomlTableKind records how a table or array of tables was defined, which determines whether it may be extended.
+type tomlTableKind int
+
+const (
+	// tomlImplicit tables are created by the header of a nested table and may be defined later.
+	tomlImplicit tomlTableKind = iota + 1
+	// tomlDotted tables are created by dotted keys and may only be extended by further dotted keys.
+	tomlDotted
+	// tomlExplicit tables are defined by a [table] header.
+	tomlExplicit
+	// tomlInline tables are defined by an inline table value and cannot be extended.
+	tomlInline
+	// tomlArray arrays of tables are defined by [[array]] headers and may be appended to.
+	tomlArray
+)
+
+// tomlKey identifies a key within a table for tracking how its value was defined.
+type tomlKey struct {
+	table uintptr
+	key   string
+}
+
+// tomlParser parses TOML documents into config maps. Integers and floats are kept as json.Number and
+// date-times as RFC 3339 strings, so that they keep their full precision.
+type tomlParser struct {
+	text    string
+	pos     int
+	root    map[string]interface{}
+	current map[string]interface{}
+	kinds   map[tomlKey]tomlTableKind
+}
+
+// decodeTOMLConfig decodes a TOML config document.
+func decodeTOMLConfig(data []byte) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	p := &tomlParser{
+		text:    strings.TrimPrefix(string(data), "\ufeff"),
+		root:    root,
+		current: root,
+		kinds:   make(map[tomlKey]tomlTableKind),
+	}
+
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// parse parses all statements (tables, arrays of tables and key/value pairs) of the document.
+func (p *tomlParser) parse() error {
+	for {
+		p.skipBlank(true)
+		if p.pos >= len(p.text) {
+			return nil
+		}
+
+		var err error
+		switch {
+		case strings.HasPrefix(p.text[p.pos:], "[["):
+			err = p.parseTableHeader(true)
+		case p.text[p.pos] == '[':
+			err = p.parseTableHeader(false)
+		default:
+			err = p.parseKeyValue(p.current)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Each statement must end with the line
+		p.skipBlank(false)
+		if p.pos < len(p.text) && p.text[p.pos] != '\n' && !strings.HasPrefix(p.text[p.pos:], "\r\n") {
+			return p.errorf("expected a new line, got %q", p.text[p.pos])
+		}
+	}
+}
+
+// parseTableHeader parses a [table] or [[array]] header and makes the table it defines the current one.
+func (p *tomlParser) parseTableHeader(array bool) error {
+	start := p.pos
+	if array {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	p.skipBlank(false)
+	if !strings.HasPrefix(p.text[p.pos:], closing) {
+		return p.errorf("expected %q to close the table header", closing)
+	}
+	// Report definition errors at the start of the header
+	end := p.pos + len(closing)
+	p.pos = start
+
+	parent := p.root
+	for _, key := range keys[:len(keys)-1] {
+		if parent, err = p.childTable(parent, key, tomlImplicit); err != nil {
+			return err
+		}
+	}
+
+	last := keys[len(keys)-1]
+	id := tomlKey{table: reflect.ValueOf(parent).Pointer(), key: last}
+	existing, exists := parent[last]
+	table := make(map[string]interface{})
+
+	switch {
+	case array && !exists:
+		parent[last] = []interface{}{table}
+		p.kinds[id] = tomlArray
+	case array && p.kinds[id] == tomlArray:
+		parent[last] = append(existing.([]interface{}), table)
+	case !array && !exists:
+		parent[last] = table
+		p.kinds[id] = tomlExplicit
+	case !array && p.kinds[id] == tomlImplicit:
+		if existingTable, ok := existing.(map[string]interface{}); ok {
+			table = existingTable
+			p.kinds[id] = tomlExplicit
+			break
+		}
+		fallthrough
+	default:
+		return p.errorf("key %q is already defined", strings.Join(keys, "."))
+	}
+
+	p.current = table
+	p.pos = end
+	return nil
+}
+
+// childTable returns the table at key in parent for navigating a header or dotted key, creating it with
+// the given kind if it does not exist. Arrays of tables resolve to their last table.
+func (p *tomlParser) childTable(parent map[string]interface{}, key string, kind tomlTableKind) (map[string]interface{}, error) {
+	id := tomlKey{table: reflect.ValueOf(parent).Pointer(), key: key}
+	switch value := parent[key].(type) {
+	case nil:
+		table := make(map[string]interface{})
+		parent[key] = table
+		p.kinds[id] = kind
+		return table, nil
+	case map[string]interface{}:
+		if existing := p.kinds[id]; existing == tomlInline || (kind == tomlDotted && existing != tomlDotted) {
+			return nil, p.errorf("table %q cannot be extended", key)
+		}
+		return value, nil
+	case []interface{}:
+		if p.kinds[id] == tomlArray && kind != tomlDotted {
+			return value[len(value)-1].(map[string]interface{}), nil
+		}
+	}
+	return nil, p.errorf("key %q is already defined", key)
+}
+
+// parseKeyValue parses a key = value pair into table, creating the tables of dotted keys.
+func (p *tomlParser) parseKeyValue(table map[string]interface{}) error {
+	start := p.pos
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+
+	p.skipBlank(false)
+	if p.pos >= len(p.text) || p.text[p.pos] != '=' {
+		return p.errorf("expected '=' after key %q", strings.Join(keys, "."))
+	}
+	p.pos++
+
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	// Report definition errors at the start of the key
+	end := p.pos
+	p.pos = start
+	for _, key := range keys[:len(keys)-1] {
+		if table, err = p.childTable(table, key, tomlDotted); err != nil {
+			return err
+		}
+	}
+
+	last := keys[len(keys)-1]
+	if _, exists := table[last]; exists {
+		return p.errorf("key %q is already defined", strings.Join(keys, "."))
+	}
+	p.pos = end
+	table[last] = value
+	if _, ok := value.(map[string]interface{}); ok {
+		p.kinds[tomlKey{table: reflect.ValueOf(table).Pointer(), key: last}] = tomlInline
+	}
+	return nil
+}
+
+// parseKey parses a bare, quoted or dotted key into its segments.
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		p.skipBlank(false)
+		if p.pos >= len(p.text) {
+			return nil, p.errorf("expected a key")
+		}
+
+		switch p.text[p.pos] {
+		case '"':
+			key, err := p.parseBasicString()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		case '\'':
+			key, err := p.parseLiteralString()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		default:
+			key := tomlBareKeyPattern.FindString(p.text[p.pos:])
+			if key == "" {
+				return nil, p.errorf("expected a key, got %q", p.text[p.pos])
+			}
+			p.pos += len(key)
+			keys = append(keys, key)
+		}
+
+		p.skipBlank(false)
+		if p.pos >= len(p.text) || p.text[p.pos] != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+// parseValue parses a string, number, bool, date-time, array or inline table.
+func (p *tomlParser) parseValue() (interface{}, error) {
+	p.skipBlank(false)
+	if p.pos >= len(p.text) {
+		return nil, p.errorf("expected a value")
+	}
+
+	rest := p.text[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, `"""`):
+		return p.parseMultilineString(`"""`)
+	case strings.HasPrefix(rest, "'''"):
+		return p.parseMultilineString("'''")
+	case rest[0] == '"':
+		return p.parseBasicString()
+	case rest[0] == '\'':
+		return p.parseLiteralString()
+	case rest[0] == '[':
+		return p.parseArray()
+	case rest[0] == '{':
+		return p.parseInlineTable()
+	default:
+		return p.parseScalar()
+	}
+}
+
+// parseArray parses an array, which may span several lines and contain comments and a trailing comma.
+func (p *tomlParser) parseArray() ([]interface{}, error) {
+	p.pos++
+	result := []interface{}{}
+	for {
+		p.skipBlank(true)
+		if p.pos < len(p.text) && p.text[p.pos] == ']' {
+			p.pos++
+			return result, nil
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+
+		p.skipBlank(true)
+		switch {
+		case p.pos >= len(p.text):
+			return nil, p.errorf("unterminated array")
+		case p.text[p.pos] == ',':
+			p.pos++
+		case p.text[p.pos] != ']':
+			return nil, p.errorf("expected ',' or ']' in array, got %q", p.text[p.pos])
+		}
+	}
+}
+
+// parseInlineTable parses an inline table such as {host = "a", port = 80}.
+func (p *tomlParser) parseInlineTable() (map[string]interface{}, error) {
+	p.pos++
+	result := make(map[string]interface{})
+	for {
+		p.skipBlank(true)
+		if p.pos < len(p.text) && p.text[p.pos] == '}' {
+			p.pos++
+			return result, nil
+		}
+
+		if err := p.parseKeyValue(result); err != nil {
+			return nil, err
+		}
+
+		p.skipBlank(true)
+		switch {
+		case p.pos >= len(p.text):
+			return nil, p.errorf("unterminated inline table")
+		case p.text[p.pos] == ',':
+			p.pos++
+		case p.text[p.pos] != '}':
+			return nil, p.errorf("expected ',' or '}' in inline table, got %q", p.text[p.pos])
+		}
+	}
+}
+
+// parseScalar parses a bool, number or date-time.
+func (p *tomlParser) parseScalar() (interface{}, error) {
+	end := strings.IndexAny(p.text[p.pos:], " \t\r\n,]}#")
+	if end < 0 {
+		end = len(p.text) - p.pos
+	}
+	token := p.text[p.pos : p.pos+end]
+
+	// A date and time may be separated by a space
+	if tomlDateTimePattern.MatchString(token) && len(token) == 10 && strings.HasPrefix(p.text[p.pos+end:], " ") {
+		timeEnd := strings.IndexAny(p.text[p.pos+end+1:], " \t\r\n,]}#")
+		if timeEnd < 0 {
+			timeEnd = len(p.text) - p.pos - end - 1
+		}
+		if candidate := p.text[p.pos : p.pos+end+1+timeEnd]; tomlDateTimePattern.MatchString(candidate) {
+			token = candidate
+		}
+	}
+
+	value, err := parseTOMLScalar(token)
+	if err != nil {
+		return nil, p.errorf("%s", err.Error())
+	}
+	p.pos += len(token)
+	return value, nil
+}
+
+// parseTOMLScalar converts a bool, number or date-time token into a raw config value.
+func parseTOMLScalar(token string) (interface{}, error) {
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+
+	base := 0
+	switch {
+	case tomlDecimalPattern.MatchString(token):
+		base = 10
+	case tomlHexPattern.MatchString(token):
+		base = 16
+	case tomlOctalPattern.MatchString(token):
+		base = 8
+	case tomlBinaryPattern.MatchString(token):
+		base = 2
+	}
+	if base != 0 {
+		digits := strings.ReplaceAll(token, "_", "")
+		if base != 10 {
+			digits = digits[2:]
+		}
+		i, err := strconv.ParseInt(digits, base, 64)
+		if err != nil {
+			return nil, fmt.Errorf("integer %s out of range", token)
+		}
+		return json.Number(strconv.FormatInt(i, 10)), nil
+	}
+
+	if tomlFloatPattern.MatchString(token) {
+		return json.Number(strings.TrimPrefix(strings.ReplaceAll(token, "_", ""), "+")), nil
+	}
+
+	if token != "" && tomlDateTimePattern.MatchString(token) {
+		return parseTOMLDateTime(token)
+	}
+
+	return nil, fmt.Errorf("invalid value %q", token)
+}
+
+// parseTOMLDateTime validates an offset date-time, local date-time, local date or local time
+// and returns it in RFC 3339 syntax (e.g., 1979-05-27T07:32:00Z).
+func parseTOMLDateTime(token string) (interface{}, error) {
+	match := tomlDateTimePattern.FindStringSubmatch(token)
+	date, separator, clock, offset := match[1], match[2], match[3], strings.ToUpper(match[5])
+
+	var layout string
+	switch {
+	case date != "" && clock != "" && separator != "":
+		layout = "2006-01-02T15:04:05.999999999"
+		if offset != "" {
+			layout += "Z07:00"
+		}
+	case date != "" && clock == "" && separator == "" && offset == "":
+		layout = time.DateOnly
+	case date == "" && clock != "" && separator == "" && offset == "":
+		layout = "15:04:05.999999999"
+	default:
+		return nil, fmt.Errorf("invalid date-time %q", token)
+	}
+
+	normalized := date
+	if separator != "" {
+		normalized += "T"
+	}
+	normalized += clock + offset
+	if _, err := time.Parse(layout, normalized); err != nil {
+		return nil, fmt.Errorf("invalid date-time %q", token)
+	}
+	return normalized, nil
+}
+
+// parseBasicString parses a double-quoted string with escape sequences.
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c == '\n':
+			return "", p.errorf("unterminated string")
+		case c == '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// parseLiteralString parses a single-quoted string without escape sequences.
+func (p *tomlParser) parseLiteralString() (string, error) {
+	start := p.pos + 1
+	end := strings.IndexAny(p.text[start:], "'\n")
+	if end < 0 || p.text[start+end] != '\'' {
+		return "", p.errorf("unterminated string")
+	}
+	p.pos = start + end + 1
+	return p.text[start : start+end], nil
+}
+
+// parseMultilineString parses a multi-line basic or literal string, delimited by three double or single quotes.
+// A new line directly following the opening delimiter is trimmed, as is a line-ending backslash in basic strings.
+func (p *tomlParser) parseMultilineString(delimiter string) (string, error) {
+	p.pos += len(delimiter)
+	if strings.HasPrefix(p.text[p.pos:], "\r\n") {
+		p.pos += 2
+	} else if strings.HasPrefix(p.text[p.pos:], "\n") {
+		p.pos++
+	}
+
+	var b strings.Builder
+	for p.pos < len(p.text) {
+		if strings.HasPrefix(p.text[p.pos:], delimiter) {
+			// Up to two quotes directly before the closing delimiter belong to the content
+			end := p.pos + len(delimiter)
+			for extra := 0; extra < 2 && end < len(p.text) && p.text[end] == delimiter[0]; extra++ {
+				b.WriteByte(delimiter[0])
+				end++
+			}
+			p.pos = end
+			return b.String(), nil
+		}
+
+		c := p.text[p.pos]
+		if c != '\\' || delimiter == "'''" {
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+
+		// A line-ending backslash trims all whitespace up to the next non-whitespace character
+		if rest := strings.TrimLeft(p.text[p.pos+1:], " \t"); strings.HasPrefix(rest, "\n") || strings.HasPrefix(rest, "\r\n") {
+			p.pos = len(p.text) - len(strings.TrimLeft(rest, " \t\r\n"))
+			continue
+		}
+		if err := p.parseEscape(&b); err != nil {
+			return "", err
+		}
+	}
+	return "", p.errorf("unterminated multi-line string")
+}
+
+// parseEscape parses the escape sequence at the current backslash and writes its value to b.
+func (p *tomlParser) parseEscape(b *strings.Builder) error {
+	if p.pos+1 >= len(p.text) {
+		return p.errorf("unterminated escape sequence")
+	}
+
+	escape := p.text[p.pos+1]
+	if value, ok := tomlEscapes[escape]; ok {
+		b.WriteString(value)
+		p.pos += 2
+		return nil
+	}
+
+	size := 0
+	switch escape {
+	case 'x':
+		size = 2
+	case 'u':
+		size = 4
+	case 'U':
+		size = 8
+	default:
+		return p.errorf("invalid escape sequence \\%c", escape)
+	}
+	if p.pos+2+size > len(p.text) {
+		return p.errorf("invalid escape sequence \\%c", escape)
+	}
+
+	digits := p.text[p.pos+2 : p.pos+2+size]
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return p.errorf("invalid escape sequence \\%c%s", escape, digits)
+	}
+	b.WriteRune(rune(code))
+	p.pos += 2 + size
+	return nil
+}
+
+// tomlEscapes maps the single-character escape sequences of basic strings to their values.
+//
+//nolint:gochecknoglobals // immutable lookup table
+var tomlEscapes = map[byte]string{
+	'b': "\b", 't': "\t", 'n': "\n", 'f': "\f", 'r': "\r", 'e': "\x1b", '"': "\"", '\\': "\\",
+}
+
+// skipBlank skips spaces, tabs and comments, and new lines if newlines is set.
+func (p *tomlParser) skipBlank(newlines bool) {
+	for p.pos < len(p.text) {
+		switch c := p.text[p.pos]; {
+		case c == ' ' || c == '\t':
+			p.pos++
+		case c == '#':
+			if end := strings.IndexByte(p.text[p.pos:], '\n'); end >= 0 {
+				p.pos += end
+			} else {
+				p.pos = len(p.text)
+			}
+		case newlines && (c == '\n' || c == '\r'):
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// errorf reports a parse error at the current position.
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	line := 1 + strings.Count(p.text[:p.pos], "\n")
+	column := 1 + utf8.RuneCountInString(p.text[strings.LastIndexByte(p.text[:p.pos], '\n')+1:p.pos])
+	return fmt.Errorf("toml: line %d, column %d: %s", line, column, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,224 @@
+package appsettings
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeTOMLConfig(t *testing.T) {
+	data := []byte(`# Server settings
+name = "my-app" # trailing comment
+port = 8080
+id = 9223372036854775807
+mask = 0xFF
+mode = 0o755
+flags = 0b101
+big = 1_000_000
+ratio = 0.5
+exp = +1e-3
+debug = true
+escaped = "tab\tquote\" \u00e9"
+literal = 'C:\Users\app'
+"quoted key" = "value"
+site."google.com" = true
+created = 1979-05-27T07:32:00.999999-07:00
+local = 1979-05-27 07:32:00
+date = 1979-05-27
+clock = 07:32:00
+tags = [
+  "api", # first
+  "web",
+]
+nested = [[1, 2], ["a"]]
+point = { x = 1, y.z = 2 }
+multiline = """
+Roses are red
+Violets are \
+    blue"""
+raw = '''
+first line
+'''
+
+[database]
+host = "localhost"
+
+[database.pool]
+max = 10
+
+[[upstreams]]
+host = "a"
+port = 80
+
+[[upstreams]]
+host = "b"
+
+[upstreams.auth]
+user = "admin"
+`)
+
+	result, err := decodeTOMLConfig(data)
+	if err != nil {
+		t.Fatalf("decodeTOMLConfig() returned error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"name":       "my-app",
+		"port":       json.Number("8080"),
+		"id":         json.Number("9223372036854775807"),
+		"mask":       json.Number("255"),
+		"mode":       json.Number("493"),
+		"flags":      json.Number("5"),
+		"big":        json.Number("1000000"),
+		"ratio":      json.Number("0.5"),
+		"exp":        json.Number("1e-3"),
+		"debug":      true,
+		"escaped":    "tab\tquote\" é",
+		"literal":    `C:\Users\app`,
+		"quoted key": "value",
+		"site":       map[string]interface{}{"google.com": true},
+		"created":    "1979-05-27T07:32:00.999999-07:00",
+		"local":      "1979-05-27T07:32:00",
+		"date":       "1979-05-27",
+		"clock":      "07:32:00",
+		"tags":       []interface{}{"api", "web"},
+		"nested": []interface{}{
+			[]interface{}{json.Number("1"), json.Number("2")},
+			[]interface{}{"a"},
+		},
+		"point":     map[string]interface{}{"x": json.Number("1"), "y": map[string]interface{}{"z": json.Number("2")}},
+		"multiline": "Roses are red\nViolets are blue",
+		"raw":       "first line\n",
+		"database": map[string]interface{}{
+			"host": "localhost",
+			"pool": map[string]interface{}{"max": json.Number("10")},
+		},
+		"upstreams": []interface{}{
+			map[string]interface{}{"host": "a", "port": json.Number("80")},
+			map[string]interface{}{"host": "b", "auth": map[string]interface{}{"user": "admin"}},
+		},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		for key, value := range expected {
+			if !reflect.DeepEqual(result[key], value) {
+				t.Errorf("Key %q: expected %#v, got %#v", key, value, result[key])
+			}
+		}
+	}
+}
+
+func TestDecodeTOMLConfig_Scalars(t *testing.T) {
+	tests := map[string]interface{}{
+		"-17":                  json.Number("-17"),
+		"+0":                   json.Number("0"),
+		"3.14_15":              json.Number("3.1415"),
+		"-inf":                 math.Inf(-1),
+		"false":                false,
+		"1979-05-27t07:32:00z": "1979-05-27T07:32:00Z",
+		`""`:                   "",
+		`'''it's'''`:           "it's",
+		`""""quoted""""`:       `"quoted"`,
+	}
+
+	for text, expected := range tests {
+		result, err := decodeTOMLConfig([]byte("value = " + text))
+		if err != nil {
+			t.Errorf("decodeTOMLConfig(%q) returned error: %v", text, err)
+			continue
+		}
+		if !reflect.DeepEqual(result["value"], expected) {
+			t.Errorf("decodeTOMLConfig(%q) = %#v, expected %#v", text, result["value"], expected)
+		}
+	}
+}
+
+func TestDecodeTOMLConfig_Errors(t *testing.T) {
+	tests := map[string]string{
+		"a = 1\na = 2":            `toml: line 2, column 1: key "a" is already defined`,
+		"[a]\n[a]":                `toml: line 2, column 1: key "a" is already defined`,
+		"a = 1\n[a]":              `toml: line 2, column 1: key "a" is already defined`,
+		"a = {b = 1}\n[a.c]":      `toml: line 2, column 1: table "a" cannot be extended`,
+		"a = [1]\n[[a]]":          `toml: line 2, column 1: key "a" is already defined`,
+		"[a]\nb.c = 1\n[a.b]":     `toml: line 3, column 1: key "a.b" is already defined`,
+		"a = 1 b = 2":             `toml: line 1, column 7: expected a new line, got 'b'`,
+		"a = 007":                 `toml: line 1, column 5: invalid value "007"`,
+		"a = 9223372036854775808": `toml: line 1, column 5: integer 9223372036854775808 out of range`,
+		"a = 1979-13-27":          `toml: line 1, column 5: invalid date-time "1979-13-27"`,
+		"a = \"open":              "toml: line 1, column 10: unterminated string",
+		"a = \"\\q\"":             `toml: line 1, column 6: invalid escape sequence \q`,
+		"a = [1, 2":               "toml: line 1, column 10: unterminated array",
+		"a = [1 2]":               `toml: line 1, column 8: expected ',' or ']' in array, got '2'`,
+		"a = {b = 1 c = 2}":       `toml: line 1, column 12: expected ',' or '}' in inline table, got 'c'`,
+		"[a":                      `toml: line 1, column 3: expected "]" to close the table header`,
+		"= 1":                     `toml: line 1, column 1: expected a key, got '='`,
+		"a 1":                     `toml: line 1, column 3: expected '=' after key "a"`,
+		"a = \"\"\"open":          "toml: line 1, column 12: unterminated multi-line string",
+		"\n\nname = 'é' x":        `toml: line 3, column 12: expected a new line, got 'x'`,
+	}
+
+	for data, expected := range tests {
+		_, err := decodeTOMLConfig([]byte(data))
+		if err == nil || err.Error() != expected {
+			t.Errorf("decodeTOMLConfig(%q) = %v, expected %q", data, err, expected)
+		}
+	}
+}
+
+type TOMLConfig struct {
+	Port      int64      `json:"port"`
+	Created   time.Time  `json:"created"`
+	Upstreams []Upstream `json:"upstreams"`
+	Database  DBConfig   `json:"database"`
+}
+
+func TestLoad_TOMLConfigFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"config.json":     `{"port": 8000, "database": {"host": "json"}}`,
+		"config.toml":     "port = 9223372036854775807\ncreated = 1979-05-27T07:32:00Z\n\n[[upstreams]]\nhost = \"a\"\nport = 80\n",
+		"config.dev.toml": "[database]\nport = 5432\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	result, err := New[TOMLConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvironment("dev").
+		WithArgs([]string{"program", "--upstreams.0.port", "81"}).
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := TOMLConfig{
+		Port:      math.MaxInt64,
+		Created:   time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+		Upstreams: []Upstream{{Host: "a", Port: 81}},
+		Database:  DBConfig{Host: "json", Port: 5432},
+	}
+	if !reflect.DeepEqual(*result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, *result)
+	}
+}
+
+func TestLoad_InvalidTOMLConfigFile(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "config.toml"), []byte("port = [1"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := New[TestConfig]().WithConfigDirectory(tempDir).Load()
+	if err == nil || !strings.Contains(err.Error(), "failed to load base config: toml: line 1") {
+		t.Errorf("Expected TOML parse error, got %v", err)
+	}
+}
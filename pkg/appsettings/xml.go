@@ -0,0 +1,131 @@
+package appsettings
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xmlTextKey is the key holding the text of an XML element that also has attributes or child elements.
+const xmlTextKey = "#text"
+
+// xmlDecoder decodes XML config documents. The children of the root element map to the top-level keys,
+// nested elements to nested objects and repeated sibling elements to arrays. Attributes map to keys
+// of their element named by the attribute name with attributePrefix prepended (e.g., "@" for @host).
+// Text values are kept as strings and converted for the type of their field when loaded.
+type xmlDecoder struct {
+	attributePrefix string
+}
+
+// xmlElement is an element being decoded, holding its attributes and child elements as fields.
+type xmlElement struct {
+	name   string
+	fields map[string]interface{}
+	text   strings.Builder
+}
+
+// decodeXMLConfig decodes an XML config document with attributes named like child elements.
+func decodeXMLConfig(data []byte) (map[string]interface{}, error) {
+	return xmlDecoder{}.decode(data)
+}
+
+// decode decodes an XML config document. An empty document decodes into an empty config.
+func (d xmlDecoder) decode(data []byte) (map[string]interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+
+	var (
+		stack []*xmlElement
+		root  map[string]interface{}
+	)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return nil, fmt.Errorf("xml: line %d: %s", syntaxErr.Line, syntaxErr.Msg)
+			}
+			line, _ := decoder.InputPos()
+			return nil, fmt.Errorf("xml: line %d: %w", line, err)
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if len(stack) == 0 && root != nil {
+				line, _ := decoder.InputPos()
+				return nil, fmt.Errorf("xml: line %d: unexpected root element <%s>", line, token.Name.Local)
+			}
+			stack = append(stack, d.element(token))
+		case xml.EndElement:
+			element := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 {
+				stack[len(stack)-1].add(element.name, element.value())
+				continue
+			}
+
+			if strings.TrimSpace(element.text.String()) != "" {
+				line, _ := decoder.InputPos()
+				return nil, fmt.Errorf("xml: line %d: unexpected text in root element <%s>", line, element.name)
+			}
+			root = element.fields
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(token)
+			} else if len(bytes.TrimSpace(token)) > 0 {
+				line, _ := decoder.InputPos()
+				return nil, fmt.Errorf("xml: line %d: unexpected text outside of the root element", line)
+			}
+		default:
+		}
+	}
+
+	if root == nil {
+		return map[string]interface{}{}, nil
+	}
+	return root, nil
+}
+
+// element returns a new element for token with its attributes as fields. Namespace declarations are skipped.
+func (d xmlDecoder) element(token xml.StartElement) *xmlElement {
+	element := &xmlElement{name: token.Name.Local, fields: make(map[string]interface{})}
+	for _, attr := range token.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		element.add(d.attributePrefix+attr.Name.Local, attr.Value)
+	}
+	return element
+}
+
+// add adds a field to the element, collecting repeated fields into an array.
+func (e *xmlElement) add(name string, value interface{}) {
+	existing, ok := e.fields[name]
+	if !ok {
+		e.fields[name] = value
+		return
+	}
+	if array, ok := existing.([]interface{}); ok {
+		e.fields[name] = append(array, value)
+		return
+	}
+	e.fields[name] = []interface{}{existing, value}
+}
+
+// value returns the value of the element: its text if it has no fields, otherwise its fields
+// with any non-blank text under xmlTextKey.
+func (e *xmlElement) value() interface{} {
+	text := strings.TrimSpace(e.text.String())
+	if len(e.fields) == 0 {
+		return text
+	}
+	if text != "" {
+		e.fields[xmlTextKey] = text
+	}
+	return e.fields
+}
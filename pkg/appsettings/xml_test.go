@@ -0,0 +1,190 @@
+package appsettings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeXMLConfig(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!-- Application settings -->
+<config xmlns="urn:example:config">
+	<name>my-app</name>
+	<port>8080</port>
+	<debug>true</debug>
+	<database host="localhost" port="5432">
+		<pool max="10"/>
+	</database>
+	<upstreams><host>a</host></upstreams>
+	<upstreams><host>b</host></upstreams>
+	<label lang="en">Hello &amp; welcome</label>
+	<empty/>
+</config>
+`)
+
+	result, err := decodeXMLConfig(data)
+	if err != nil {
+		t.Fatalf("decodeXMLConfig() returned error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"name":  "my-app",
+		"port":  "8080",
+		"debug": "true",
+		"database": map[string]interface{}{
+			"host": "localhost",
+			"port": "5432",
+			"pool": map[string]interface{}{"max": "10"},
+		},
+		"upstreams": []interface{}{
+			map[string]interface{}{"host": "a"},
+			map[string]interface{}{"host": "b"},
+		},
+		"label": map[string]interface{}{"lang": "en", xmlTextKey: "Hello & welcome"},
+		"empty": "",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestDecodeXMLConfig_AttributePrefix(t *testing.T) {
+	result, err := xmlDecoder{attributePrefix: "@"}.decode([]byte(`<config><database host="localhost"><host>ignored</host></database></config>`))
+	if err != nil {
+		t.Fatalf("decode() returned error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"database": map[string]interface{}{"@host": "localhost", "host": "ignored"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestDecodeXMLConfig_Empty(t *testing.T) {
+	for _, data := range []string{"", "<?xml version=\"1.0\"?>\n", "<config/>"} {
+		result, err := decodeXMLConfig([]byte(data))
+		if err != nil || len(result) != 0 {
+			t.Errorf("decodeXMLConfig(%q) = (%v, %v), expected empty config", data, result, err)
+		}
+	}
+}
+
+func TestDecodeXMLConfig_Errors(t *testing.T) {
+	tests := map[string]string{
+		"<config>\n<port>1</config>":              "xml: line 2: element <port> closed by </config>",
+		"<config>\n<port>":                        "xml: line 2: unexpected EOF",
+		"<a></a>\n<b></b>":                        "xml: line 2: unexpected root element <b>",
+		"<config>text</config>":                   "xml: line 1: unexpected text in root element <config>",
+		"<config/>\ntrailing":                     "xml: line 2: unexpected text outside of the root element",
+		"<config><name>&unknown;</name></config>": "xml: line 1: invalid character entity &unknown;",
+	}
+
+	for data, expected := range tests {
+		_, err := decodeXMLConfig([]byte(data))
+		if err == nil || err.Error() != expected {
+			t.Errorf("decodeXMLConfig(%q) = %v, expected %q", data, err, expected)
+		}
+	}
+}
+
+type XMLConfig struct {
+	Name      string     `json:"name"`
+	Tags      []string   `json:"tag"`
+	Upstreams []Upstream `json:"upstream"`
+	Database  struct {
+		Host string `json:"@host"`
+		Port int    `json:"@port"`
+	} `json:"database"`
+}
+
+func TestLoad_XMLConfigFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"config.xml": `<config>
+	<name>xml</name>
+	<tag>a</tag>
+	<upstream><host>a</host><port>80</port></upstream>
+	<database host="localhost" port="5432"/>
+</config>`,
+		"config.dev.xml": `<config><tag>b</tag><tag>c</tag></config>`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	result, err := New[XMLConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvironment("dev").
+		WithXMLAttributePrefix("@").
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.Name != "xml" || result.Database.Host != "localhost" || result.Database.Port != 5432 {
+		t.Errorf("Unexpected config %+v", *result)
+	}
+	if !reflect.DeepEqual(result.Tags, []string{"b", "c"}) {
+		t.Errorf("Expected tags from env config, got %v", result.Tags)
+	}
+	if !reflect.DeepEqual(result.Upstreams, []Upstream{{Host: "a", Port: 80}}) {
+		t.Errorf("Expected single upstream element to decode into a slice, got %v", result.Upstreams)
+	}
+}
+
+func TestLoad_XMLConfigFileTypedValues(t *testing.T) {
+	type Config struct {
+		Retries  int    `json:"retries"`
+		Enabled  bool   `json:"enabled"`
+		Password string `json:"password"`
+		Ports    []int  `json:"port"`
+		Database struct {
+			User string `json:"user"`
+			Pool int    `json:"pool"`
+		} `json:"database"`
+	}
+
+	tempDir := t.TempDir()
+	content := `<config>
+	<retries>1</retries>
+	<enabled>1</enabled>
+	<password>123456</password>
+	<port>0</port>
+	<database user="1" pool="0"/>
+</config>`
+	if err := os.WriteFile(filepath.Join(tempDir, "config.xml"), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	result, err := New[Config]().WithConfigDirectory(tempDir).Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.Retries != 1 || !result.Enabled || result.Password != "123456" || !reflect.DeepEqual(result.Ports, []int{0}) {
+		t.Errorf("Unexpected config %+v", *result)
+	}
+	if result.Database.User != "1" || result.Database.Pool != 0 {
+		t.Errorf("Expected attributes converted for their fields, got %+v", result.Database)
+	}
+}
+
+func TestLoad_InvalidXMLConfigFile(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "config.xml"), []byte("<config>"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := New[TestConfig]().WithConfigDirectory(tempDir).Load()
+	if err == nil || !strings.Contains(err.Error(), "failed to load base config: xml: line 1") {
+		t.Errorf("Expected XML parse error, got %v", err)
+	}
+}
@@ -0,0 +1,760 @@
+package appsettings
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//nolint:gochecknoglobals // immutable patterns of the YAML 1.2 core schema
+var (
+	yamlIntPattern   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlFloatPattern = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// yamlLine is a line of a YAML document.
+type yamlLine struct {
+	// number is the 1-based line number used in error messages.
+	number int
+	// indent is the number of leading spaces.
+	indent int
+	// text is the line without indentation and trailing whitespace.
+	text string
+}
+
+// yamlParser parses the common subset of YAML used in config files: block mappings and sequences,
+// flow collections, plain and quoted scalars, literal and folded block scalars and comments.
+// Anchors, aliases, tags, complex keys and multiple documents are rejected with an error.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// decodeYAMLConfig decodes a YAML config document. Numbers are kept as json.Number like in JSON config files.
+func decodeYAMLConfig(data []byte) (map[string]interface{}, error) {
+	text := strings.TrimPrefix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\ufeff")
+
+	p := &yamlParser{}
+	for i, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		p.lines = append(p.lines, yamlLine{
+			number: i + 1,
+			indent: len(line) - len(trimmed),
+			text:   strings.TrimRight(trimmed, " \t\r"),
+		})
+	}
+
+	value, err := p.parseDocument()
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	config, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("yaml: config must be a mapping, got %s", rawKind(value))
+	}
+	return config, nil
+}
+
+// parseDocument parses a single YAML document with optional directives and document markers.
+func (p *yamlParser) parseDocument() (interface{}, error) {
+	for line := p.peek(); line != nil && strings.HasPrefix(line.text, "%"); line = p.peek() {
+		p.pos++ // Directives such as %YAML 1.2 do not affect the supported subset
+	}
+	if line := p.peek(); line != nil && isYAMLDocumentMarker(line, "---") {
+		if rest := stripYAMLComment(strings.TrimPrefix(line.text, "---")); strings.TrimSpace(rest) != "" {
+			return nil, yamlError(line, "content after document start marker is not supported")
+		}
+		p.pos++
+	}
+
+	value, err := p.parseNode(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	if line := p.peek(); line != nil {
+		switch {
+		case isYAMLDocumentMarker(line, "..."):
+			p.pos++
+			if next := p.peek(); next != nil && !isYAMLDocumentMarker(next, "---") {
+				return nil, yamlError(next, "content after document end marker")
+			}
+			if p.peek() != nil {
+				return nil, yamlError(p.peek(), "multiple documents are not supported")
+			}
+		case isYAMLDocumentMarker(line, "---"):
+			return nil, yamlError(line, "multiple documents are not supported")
+		default:
+			return nil, yamlError(line, "unexpected content")
+		}
+	}
+
+	return value, nil
+}
+
+// peek returns the next line holding content, skipping blank and comment lines, or nil at the end of the document.
+func (p *yamlParser) peek() *yamlLine {
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := &p.lines[p.pos]
+		if text := strings.TrimLeft(line.text, "\t"); text != "" && !strings.HasPrefix(text, "#") {
+			return line
+		}
+	}
+	return nil
+}
+
+// parseNode parses the block node starting at the next line if it is indented more than parentIndent.
+// It returns nil if there is no such node.
+func (p *yamlParser) parseNode(parentIndent int) (interface{}, error) {
+	line := p.peek()
+	if line == nil || line.indent <= parentIndent || isYAMLDocumentMarker(line, "---") || isYAMLDocumentMarker(line, "...") {
+		return nil, nil
+	}
+	switch {
+	case isYAMLSequenceItem(line.text):
+		return p.parseSequence(line.indent)
+	case hasYAMLKey(line.text):
+		return p.parseMapping(line.indent)
+	default:
+		p.pos++
+		return p.parseValue(line.text, line, parentIndent, false)
+	}
+}
+
+// parseMapping parses the entries of a block mapping at indent.
+func (p *yamlParser) parseMapping(indent int) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	for line := p.peek(); line != nil && line.indent >= indent; line = p.peek() {
+		if isYAMLDocumentMarker(line, "---") || isYAMLDocumentMarker(line, "...") {
+			break
+		}
+		if line.indent > indent || strings.HasPrefix(line.text, "\t") {
+			return nil, yamlError(line, "unexpected indentation")
+		}
+		if isYAMLSequenceItem(line.text) {
+			return nil, yamlError(line, "unexpected sequence item in mapping")
+		}
+
+		key, rest, ok, err := splitYAMLKey(line.text)
+		if err != nil {
+			return nil, yamlError(line, err.Error())
+		}
+		if !ok {
+			return nil, yamlError(line, "expected a mapping key")
+		}
+		if _, duplicate := result[key]; duplicate {
+			return nil, yamlError(line, fmt.Sprintf("duplicate key %q", key))
+		}
+
+		p.pos++
+		value, err := p.parseValue(rest, line, indent, true)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// parseSequence parses the items of a block sequence at indent.
+func (p *yamlParser) parseSequence(indent int) ([]interface{}, error) {
+	result := []interface{}{}
+	for line := p.peek(); line != nil && line.indent >= indent; line = p.peek() {
+		if line.indent > indent || strings.HasPrefix(line.text, "\t") {
+			return nil, yamlError(line, "unexpected indentation")
+		}
+		if !isYAMLSequenceItem(line.text) {
+			break
+		}
+
+		rest := strings.TrimLeft(line.text[1:], " ")
+		var (
+			item interface{}
+			err  error
+		)
+		switch {
+		case rest == "" || strings.HasPrefix(rest, "#"):
+			p.pos++
+			item, err = p.parseNode(indent)
+		case isYAMLSequenceItem(rest) || hasYAMLKey(rest):
+			// A nested collection starting on the item line is indented by the position of its first key
+			p.lines[p.pos] = yamlLine{number: line.number, indent: indent + len(line.text) - len(rest), text: rest}
+			item, err = p.parseNode(indent)
+		default:
+			p.pos++
+			item, err = p.parseValue(rest, line, indent, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// parseValue parses the value following a mapping key or sequence indicator on line. An empty value
+// is followed by a nested block node; in a mapping, a sequence may also start at the indent of the key.
+func (p *yamlParser) parseValue(text string, line *yamlLine, indent int, inMapping bool) (interface{}, error) {
+	text = strings.TrimSpace(stripYAMLComment(text))
+	if text == "" {
+		if next := p.peek(); inMapping && next != nil && next.indent == indent && isYAMLSequenceItem(next.text) {
+			return p.parseSequence(indent)
+		}
+		return p.parseNode(indent)
+	}
+
+	switch text[0] {
+	case '|', '>':
+		return p.parseBlockScalar(text, line, indent)
+	case '&', '*', '!':
+		return nil, yamlError(line, "anchors, aliases and tags are not supported")
+	case '[', '{':
+		// Flow collections may span several lines until their brackets are balanced
+		for depth := yamlFlowDepth(text); depth > 0; depth = yamlFlowDepth(text) {
+			if p.pos >= len(p.lines) {
+				return nil, yamlError(line, "unterminated flow collection")
+			}
+			text += " " + strings.TrimSpace(stripYAMLComment(p.lines[p.pos].text))
+			p.pos++
+		}
+		flow := &yamlFlow{text: text}
+		value, err := flow.parse()
+		if err != nil {
+			return nil, yamlError(line, err.Error())
+		}
+		return value, nil
+	default:
+		value, err := parseYAMLScalar(text)
+		if err != nil {
+			return nil, yamlError(line, err.Error())
+		}
+		return value, nil
+	}
+}
+
+// parseBlockScalar parses a literal (|) or folded (>) block scalar with the given header,
+// whose content lines are indented more than parentIndent.
+func (p *yamlParser) parseBlockScalar(header string, line *yamlLine, parentIndent int) (interface{}, error) {
+	folded, chomping, contentIndent := header[0] == '>', byte(0), 0
+	for _, indicator := range header[1:] {
+		switch {
+		case (indicator == '-' || indicator == '+') && chomping == 0:
+			chomping = byte(indicator)
+		case indicator >= '1' && indicator <= '9' && contentIndent == 0:
+			contentIndent = max(parentIndent, 0) + int(indicator-'0')
+		default:
+			return nil, yamlError(line, fmt.Sprintf("invalid block scalar header %q", header))
+		}
+	}
+
+	var content []string
+	for ; p.pos < len(p.lines); p.pos++ {
+		next := p.lines[p.pos]
+		if next.text == "" {
+			content = append(content, "")
+			continue
+		}
+		if contentIndent == 0 {
+			if next.indent <= parentIndent {
+				break
+			}
+			contentIndent = next.indent
+		}
+		if next.indent < contentIndent {
+			break
+		}
+		content = append(content, strings.Repeat(" ", next.indent-contentIndent)+next.text)
+	}
+
+	// Separate trailing blank lines, which are only kept with the keep (+) chomping indicator
+	body := len(content)
+	for body > 0 && content[body-1] == "" {
+		body--
+	}
+	trailing := len(content) - body
+	content = content[:body]
+
+	var b strings.Builder
+	for i, text := range content {
+		if i > 0 {
+			previous := content[i-1]
+			switch {
+			case !folded:
+				b.WriteString("\n")
+			case text == "" || previous == "":
+				// Each empty line is folded into a single line break
+			case strings.HasPrefix(text, " ") || strings.HasPrefix(previous, " "):
+				b.WriteString("\n")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		if folded && text == "" {
+			b.WriteString("\n")
+		}
+		b.WriteString(text)
+	}
+
+	if body > 0 {
+		switch chomping {
+		case '-':
+		case '+':
+			b.WriteString(strings.Repeat("\n", trailing+1))
+		default:
+			b.WriteString("\n")
+		}
+	}
+	return b.String(), nil
+}
+
+// yamlFlow parses a flow collection (e.g., [a, b] or {a: 1}) from a single string.
+type yamlFlow struct {
+	text string
+	pos  int
+}
+
+// parse parses the flow collection and checks that no content follows it.
+func (f *yamlFlow) parse() (interface{}, error) {
+	value, err := f.value()
+	if err != nil {
+		return nil, err
+	}
+	f.skipSpaces()
+	if f.pos < len(f.text) {
+		return nil, fmt.Errorf("unexpected %q after flow collection", f.text[f.pos:])
+	}
+	return value, nil
+}
+
+// value parses a flow collection or scalar at the current position.
+func (f *yamlFlow) value() (interface{}, error) {
+	f.skipSpaces()
+	if f.pos >= len(f.text) {
+		return nil, fmt.Errorf("unexpected end of flow collection")
+	}
+
+	switch f.text[f.pos] {
+	case '[':
+		return f.sequence()
+	case '{':
+		return f.mapping()
+	case '"', '\'':
+		value, end, err := parseYAMLQuoted(f.text[f.pos:])
+		if err != nil {
+			return nil, err
+		}
+		f.pos += end
+		return value, nil
+	case '&', '*', '!':
+		return nil, fmt.Errorf("anchors, aliases and tags are not supported")
+	default:
+		return parseYAMLScalar(f.plain())
+	}
+}
+
+// sequence parses a flow sequence. A trailing comma is allowed.
+func (f *yamlFlow) sequence() ([]interface{}, error) {
+	f.pos++
+	result := []interface{}{}
+	for {
+		f.skipSpaces()
+		if f.pos < len(f.text) && f.text[f.pos] == ']' {
+			f.pos++
+			return result, nil
+		}
+
+		item, err := f.value()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+
+		if err := f.separator(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// mapping parses a flow mapping. Keys without a value are null and a trailing comma is allowed.
+func (f *yamlFlow) mapping() (map[string]interface{}, error) {
+	f.pos++
+	result := make(map[string]interface{})
+	for {
+		f.skipSpaces()
+		if f.pos < len(f.text) && f.text[f.pos] == '}' {
+			f.pos++
+			return result, nil
+		}
+
+		var key string
+		if f.pos < len(f.text) && (f.text[f.pos] == '"' || f.text[f.pos] == '\'') {
+			quoted, end, err := parseYAMLQuoted(f.text[f.pos:])
+			if err != nil {
+				return nil, err
+			}
+			key, f.pos = quoted, f.pos+end
+		} else {
+			key = f.plain()
+		}
+		if _, duplicate := result[key]; duplicate {
+			return nil, fmt.Errorf("duplicate key %q", key)
+		}
+
+		f.skipSpaces()
+		if f.pos < len(f.text) && f.text[f.pos] == ':' {
+			f.pos++
+			f.skipSpaces()
+			if f.pos < len(f.text) && f.text[f.pos] != ',' && f.text[f.pos] != '}' {
+				value, err := f.value()
+				if err != nil {
+					return nil, err
+				}
+				result[key] = value
+			} else {
+				result[key] = nil
+			}
+		} else {
+			result[key] = nil
+		}
+
+		if err := f.separator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// separator consumes the comma between flow entries, leaving a closing bracket for the caller.
+func (f *yamlFlow) separator(closing byte) error {
+	f.skipSpaces()
+	switch {
+	case f.pos >= len(f.text):
+		return fmt.Errorf("unterminated flow collection, expected %q", closing)
+	case f.text[f.pos] == ',':
+		f.pos++
+		return nil
+	case f.text[f.pos] == closing:
+		return nil
+	default:
+		return fmt.Errorf("unexpected %q in flow collection, expected ',' or %q", f.text[f.pos], closing)
+	}
+}
+
+// plain reads a plain scalar, which ends at a flow indicator or a colon followed by a space.
+func (f *yamlFlow) plain() string {
+	start := f.pos
+	for ; f.pos < len(f.text); f.pos++ {
+		c := f.text[f.pos]
+		if c == ',' || c == '[' || c == ']' || c == '{' || c == '}' {
+			break
+		}
+		if c == ':' && (f.pos+1 == len(f.text) || strings.ContainsRune(" ,]}", rune(f.text[f.pos+1]))) {
+			break
+		}
+	}
+	return strings.TrimSpace(f.text[start:f.pos])
+}
+
+// skipSpaces advances past whitespace.
+func (f *yamlFlow) skipSpaces() {
+	for f.pos < len(f.text) && (f.text[f.pos] == ' ' || f.text[f.pos] == '\t') {
+		f.pos++
+	}
+}
+
+// parseYAMLScalar parses a quoted scalar or a plain scalar, which keeps its source text unless it is null.
+func parseYAMLScalar(text string) (interface{}, error) {
+	if text != "" && (text[0] == '"' || text[0] == '\'') {
+		value, end, err := parseYAMLQuoted(text)
+		if err != nil {
+			return nil, err
+		}
+		if rest := strings.TrimSpace(text[end:]); rest != "" {
+			return nil, fmt.Errorf("unexpected %q after quoted scalar", rest)
+		}
+		return value, nil
+	}
+
+	if strings.Contains(text, ": ") {
+		return nil, fmt.Errorf("mapping values are not allowed in this context")
+	}
+	if resolveYAMLPlain(text) == nil {
+		return nil, nil
+	}
+	return plainScalar(text), nil
+}
+
+// plainScalar is the source text of a plain YAML scalar. Whether it is a number, a bool or a string depends on
+// the field it is loaded into (e.g., version: 1.10 is the string "1.10" for a string field), so it is resolved
+// when the config is canonicalized against the config type.
+type plainScalar string
+
+// resolveYAMLScalar resolves the plain scalar text for type t. Fields holding text (e.g., strings or types
+// with a registered decoder or a TextUnmarshaler) receive the source text; others the value resolved by the
+// YAML 1.2 core schema.
+func resolveYAMLScalar(text string, t reflect.Type) interface{} {
+	value := resolveYAMLPlain(text)
+	if _, ok := value.(string); ok || t == nil {
+		return value
+	}
+
+	base := elemType(t)
+	if base.Kind() == reflect.String || hasRegisteredDecoder(t) || reflect.PointerTo(base).Implements(textUnmarshalerType) {
+		return text
+	}
+	return value
+}
+
+// resolvePlainScalars resolves the plain scalars in raw, which are not loaded into a typed field (e.g., values
+// of any fields or of unknown keys), following the YAML 1.2 core schema.
+func resolvePlainScalars(raw interface{}) interface{} {
+	switch value := raw.(type) {
+	case plainScalar:
+		return resolveYAMLPlain(string(value))
+	case map[string]interface{}:
+		for key, elem := range value {
+			value[key] = resolvePlainScalars(elem)
+		}
+	case []interface{}:
+		for i, elem := range value {
+			value[i] = resolvePlainScalars(elem)
+		}
+	default:
+	}
+	return raw
+}
+
+// resolveYAMLPlain resolves a plain scalar to null, a bool, a number or a string following the YAML 1.2 core schema.
+// Numbers are returned as json.Number in JSON syntax, so that integers keep their full precision.
+func resolveYAMLPlain(text string) interface{} {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+
+	integer := new(big.Int)
+	switch {
+	case yamlIntPattern.MatchString(text):
+		integer.SetString(strings.TrimPrefix(text, "+"), 10)
+		return json.Number(integer.String())
+	case strings.HasPrefix(text, "0o"):
+		if _, ok := integer.SetString(text[2:], 8); ok {
+			return json.Number(integer.String())
+		}
+	case strings.HasPrefix(text, "0x"):
+		if _, ok := integer.SetString(text[2:], 16); ok {
+			return json.Number(integer.String())
+		}
+	case yamlFloatPattern.MatchString(text):
+		return json.Number(normalizeYAMLFloat(text))
+	}
+
+	return text
+}
+
+// normalizeYAMLFloat converts a YAML float (e.g., +.5 or 1.e3) into JSON number syntax (e.g., 0.5 or 1.0e3).
+func normalizeYAMLFloat(text string) string {
+	text = strings.TrimPrefix(text, "+")
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	if strings.HasPrefix(text, ".") {
+		text = "0" + text
+	}
+	if i := strings.IndexByte(text, '.'); i >= 0 && (i+1 == len(text) || text[i+1] == 'e' || text[i+1] == 'E') {
+		text = text[:i+1] + "0" + text[i+1:]
+	}
+	return sign + text
+}
+
+// parseYAMLQuoted parses the single- or double-quoted scalar at the start of text
+// and returns its value and the index following the closing quote.
+func parseYAMLQuoted(text string) (string, int, error) {
+	var b strings.Builder
+	if text[0] == '\'' {
+		for i := 1; i < len(text); i++ {
+			if text[i] != '\'' {
+				b.WriteByte(text[i])
+				continue
+			}
+			if i+1 < len(text) && text[i+1] == '\'' {
+				b.WriteByte('\'')
+				i++
+				continue
+			}
+			return b.String(), i + 1, nil
+		}
+		return "", 0, fmt.Errorf("unterminated single-quoted scalar")
+	}
+
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(text) {
+				return "", 0, fmt.Errorf("unterminated double-quoted scalar")
+			}
+			i++
+			if r, ok := yamlEscapes[text[i]]; ok {
+				b.WriteString(r)
+				continue
+			}
+
+			size := 0
+			switch text[i] {
+			case 'x':
+				size = 2
+			case 'u':
+				size = 4
+			case 'U':
+				size = 8
+			default:
+			}
+			if size == 0 || i+size >= len(text) {
+				return "", 0, fmt.Errorf("invalid escape sequence \\%c", text[i])
+			}
+			code, err := strconv.ParseUint(text[i+1:i+1+size], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(code)) {
+				return "", 0, fmt.Errorf("invalid escape sequence \\%s", text[i:i+1+size])
+			}
+			b.WriteRune(rune(code))
+			i += size
+		default:
+			b.WriteByte(text[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated double-quoted scalar")
+}
+
+// yamlEscapes maps the single-character escape sequences of double-quoted scalars to their values.
+//
+//nolint:gochecknoglobals // immutable lookup table
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f", 'r': "\r",
+	'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\", 'N': "\u0085", '_': " ", 'L': " ", 'P': " ",
+}
+
+// splitYAMLKey splits a mapping entry into its key and the remaining value text.
+// It reports false if text does not start with a key followed by a colon and a space or the end of the line.
+func splitYAMLKey(text string) (string, string, bool, error) {
+	text = stripYAMLComment(text)
+	if text == "" {
+		return "", "", false, nil
+	}
+
+	if text[0] == '"' || text[0] == '\'' {
+		key, end, err := parseYAMLQuoted(text)
+		if err != nil {
+			return "", "", false, err
+		}
+		rest := strings.TrimLeft(text[end:], " ")
+		if rest == ":" || strings.HasPrefix(rest, ": ") {
+			return key, rest[1:], true, nil
+		}
+		return "", "", false, nil
+	}
+
+	if strings.ContainsRune("[{&*!|>", rune(text[0])) {
+		return "", "", false, nil
+	}
+	for i := range len(text) {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return strings.TrimSpace(text[:i]), text[i+1:], true, nil
+		}
+	}
+	return "", "", false, nil
+}
+
+// hasYAMLKey reports whether text starts with a mapping key.
+func hasYAMLKey(text string) bool {
+	_, _, ok, err := splitYAMLKey(text)
+	return ok || err != nil
+}
+
+// isYAMLSequenceItem reports whether text starts with a block sequence indicator.
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// isYAMLDocumentMarker reports whether line is the document start (---) or end (...) marker.
+func isYAMLDocumentMarker(line *yamlLine, marker string) bool {
+	return line.indent == 0 && (line.text == marker || strings.HasPrefix(line.text, marker+" "))
+}
+
+// stripYAMLComment removes a trailing comment, which starts with a # at the beginning of text
+// or after whitespace outside of quoted scalars.
+func stripYAMLComment(text string) string {
+	end := len(text)
+	scanYAML(text, func(i int) bool {
+		if text[i] == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t') {
+			end = i
+			return false
+		}
+		return true
+	})
+	return strings.TrimRight(text[:end], " \t")
+}
+
+// yamlFlowDepth returns the number of unclosed brackets of flow collections in text, ignoring quoted scalars.
+func yamlFlowDepth(text string) int {
+	depth := 0
+	scanYAML(text, func(i int) bool {
+		switch text[i] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		default:
+		}
+		return true
+	})
+	return depth
+}
+
+// scanYAML calls visit with the index of each character of text outside of quoted scalars until visit returns false.
+// A quote only starts a quoted scalar at the beginning of text or after whitespace or a flow indicator,
+// so that apostrophes within plain scalars (e.g., it's) are not mistaken for quotes.
+func scanYAML(text string, visit func(i int) bool) {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++ // Skip the escaped character
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.ContainsRune(" \t[{,:", rune(text[i-1]))):
+			quote = c
+		case !visit(i):
+			return
+		}
+	}
+}
+
+// yamlError reports a parse error at line.
+func yamlError(line *yamlLine, message string) error {
+	return fmt.Errorf("yaml: line %d: %s", line.number, message)
+}
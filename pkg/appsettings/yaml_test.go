@@ -0,0 +1,264 @@
+package appsettings
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeYAMLConfig(t *testing.T) {
+	data := []byte(`%YAML 1.2
+---
+# Server settings
+name: my-app   # trailing comment
+port: 8080
+ratio: .5
+id: 9007199254740993
+mode: 0o755
+mask: 0x1F
+debug: true
+missing: ~
+empty:
+url: http://example.com/#anchor
+quoted: "tab\tand \u00e9 # not a comment"
+single: 'it''s'
+plain: it's fine
+"quoted key": value
+database:
+  host: localhost
+  pool:
+    max: 10
+upstreams:
+- host: a
+  port: 80
+-   host: b
+    port: 81
+tags: [api, "web", 3]
+labels: {team: platform, tier: 1,}
+matrix:
+  - [1, 2]
+  - - 3
+    - 4
+multiline: [
+  a,  # first
+  b
+]
+literal: |
+  line 1
+    indented
+
+  line 3
+folded: >-
+  folded
+  text
+
+  paragraph
+keep: |+
+  kept
+
+...
+`)
+
+	result, err := decodeYAMLConfig(data)
+	if err != nil {
+		t.Fatalf("decodeYAMLConfig() returned error: %v", err)
+	}
+	if result["ratio"] != plainScalar(".5") || result["quoted"] != "tab\tand é # not a comment" {
+		t.Errorf("Expected plain scalars to keep their source text, got %#v and %#v", result["ratio"], result["quoted"])
+	}
+	result, _ = resolvePlainScalars(result).(map[string]interface{})
+
+	expected := map[string]interface{}{
+		"name":       "my-app",
+		"port":       json.Number("8080"),
+		"ratio":      json.Number("0.5"),
+		"id":         json.Number("9007199254740993"),
+		"mode":       json.Number("493"),
+		"mask":       json.Number("31"),
+		"debug":      true,
+		"missing":    nil,
+		"empty":      nil,
+		"url":        "http://example.com/#anchor",
+		"quoted":     "tab\tand é # not a comment",
+		"single":     "it's",
+		"plain":      "it's fine",
+		"quoted key": "value",
+		"database": map[string]interface{}{
+			"host": "localhost",
+			"pool": map[string]interface{}{"max": json.Number("10")},
+		},
+		"upstreams": []interface{}{
+			map[string]interface{}{"host": "a", "port": json.Number("80")},
+			map[string]interface{}{"host": "b", "port": json.Number("81")},
+		},
+		"tags":   []interface{}{"api", "web", json.Number("3")},
+		"labels": map[string]interface{}{"team": "platform", "tier": json.Number("1")},
+		"matrix": []interface{}{
+			[]interface{}{json.Number("1"), json.Number("2")},
+			[]interface{}{json.Number("3"), json.Number("4")},
+		},
+		"multiline": []interface{}{"a", "b"},
+		"literal":   "line 1\n  indented\n\nline 3\n",
+		"folded":    "folded text\nparagraph",
+		"keep":      "kept\n\n",
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		for key, value := range expected {
+			if !reflect.DeepEqual(result[key], value) {
+				t.Errorf("Key %q: expected %#v, got %#v", key, value, result[key])
+			}
+		}
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestDecodeYAMLConfig_Scalars(t *testing.T) {
+	tests := map[string]interface{}{
+		"1.e3":  json.Number("1.0e3"),
+		"+12":   json.Number("12"),
+		"-0.25": json.Number("-0.25"),
+		"007":   json.Number("7"),
+		"1_000": "1_000",
+		"False": false,
+		"NULL":  nil,
+		"-.inf": math.Inf(-1),
+		"yes":   "yes",
+		"0x":    "0x",
+	}
+
+	for text, expected := range tests {
+		result, err := decodeYAMLConfig([]byte("value: " + text))
+		if err != nil {
+			t.Errorf("decodeYAMLConfig(%q) returned error: %v", text, err)
+			continue
+		}
+		if value := resolvePlainScalars(result["value"]); !reflect.DeepEqual(value, expected) {
+			t.Errorf("decodeYAMLConfig(%q) = %#v, expected %#v", text, value, expected)
+		}
+	}
+
+	result, err := decodeYAMLConfig([]byte("# only a comment\n"))
+	if err != nil || len(result) != 0 {
+		t.Errorf("Expected empty config for empty document, got %v, %v", result, err)
+	}
+}
+
+func TestDecodeYAMLConfig_Errors(t *testing.T) {
+	tests := map[string]string{
+		"a: 1\n  b: 2":       "yaml: line 2: unexpected indentation",
+		"a: 1\na: 2":         `yaml: line 2: duplicate key "a"`,
+		"a: 1\n- b":          "yaml: line 2: unexpected sequence item in mapping",
+		"a: &anchor 1":       "yaml: line 1: anchors, aliases and tags are not supported",
+		"a: [1, 2":           "yaml: line 1: unterminated flow collection",
+		"a: \"open":          "yaml: line 1: unterminated double-quoted scalar",
+		"a: b: c":            "yaml: line 1: mapping values are not allowed in this context",
+		"a: 1\n---\nb: 2":    "yaml: line 2: multiple documents are not supported",
+		"a: 1\n\tb: 2":       "yaml: line 2: unexpected indentation",
+		"- a\n- b":           "yaml: config must be a mapping, got array",
+		"a: {b: 1]":          `yaml: line 1: unexpected ']' in flow collection, expected ',' or '}'`,
+		"a: \"\\q\"":         `yaml: line 1: invalid escape sequence \q`,
+		"a: 1\nplain text":   "yaml: line 2: expected a mapping key",
+		"a: |x\n  text":      `yaml: line 1: invalid block scalar header "|x"`,
+		"a:\n  - 1\n  b: 2":  "yaml: line 3: unexpected indentation",
+		"  a: 1\nb: 2":       "yaml: line 2: unexpected content",
+		"a: 1\n...\nb: 2":    "yaml: line 3: content after document end marker",
+		"a: [1, 2] extra":    `yaml: line 1: unexpected "extra" after flow collection`,
+		"a: 'x' y":           `yaml: line 1: unexpected "y" after quoted scalar`,
+		"--- a: 1":           "yaml: line 1: content after document start marker is not supported",
+		"a: \"\\u12\"":       `yaml: line 1: invalid escape sequence \u`,
+		"a: \"\\UFFFFFFFF\"": `yaml: line 1: invalid escape sequence \UFFFFFFFF`,
+		"a: [b: 1]":          `yaml: line 1: unexpected ':' in flow collection, expected ',' or ']'`,
+	}
+
+	for data, expected := range tests {
+		_, err := decodeYAMLConfig([]byte(data))
+		if err == nil || err.Error() != expected {
+			t.Errorf("decodeYAMLConfig(%q) = %v, expected %q", data, err, expected)
+		}
+	}
+}
+
+func TestLoad_YAMLConfigFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"config.json":     `{"databaseUrl": "postgres://localhost/json", "port": 8000, "name": "json"}`,
+		"config.yaml":     "port: 8001\nname: yaml\n",
+		"config.yml":      "name: yml\n",
+		"config.dev.yaml": "databaseUrl: postgres://localhost/dev\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	result, err := New[TestConfig]().
+		WithConfigDirectory(tempDir).
+		WithEnvironment("dev").
+		Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.DatabaseURL != "postgres://localhost/dev" || result.Port != 8001 || result.Name != "yml" {
+		t.Errorf("Expected YAML files to be layered after JSON, got %+v", *result)
+	}
+}
+
+func TestLoad_YAMLPlainScalarsForFieldTypes(t *testing.T) {
+	type Config struct {
+		Password string                 `json:"password"`
+		Version  string                 `json:"version"`
+		Port     int                    `json:"port"`
+		Ratio    float64                `json:"ratio"`
+		Debug    bool                   `json:"debug"`
+		Timeout  time.Duration          `json:"timeout"`
+		Tags     []string               `json:"tags"`
+		Extra    map[string]interface{} `json:"extra"`
+	}
+
+	tempDir := t.TempDir()
+	content := "password: 123456\nversion: 1.10\nport: 0x1F\nratio: .5\ndebug: True\ntimeout: 1m30s\n" +
+		"tags: [1.0, true]\nextra:\n  build: 1.10\n  release: \"1.10\"\n  flag: false\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "config.yaml"), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	result, err := New[Config]().WithConfigDirectory(tempDir).Load()
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if result.Password != "123456" || result.Version != "1.10" {
+		t.Errorf("Expected string fields to keep the source text, got %q and %q", result.Password, result.Version)
+	}
+	if result.Port != 31 || result.Ratio != 0.5 || !result.Debug || result.Timeout != 90*time.Second {
+		t.Errorf("Expected typed fields to be resolved, got %+v", *result)
+	}
+	if !reflect.DeepEqual(result.Tags, []string{"1.0", "true"}) {
+		t.Errorf("Expected string elements to keep the source text, got %v", result.Tags)
+	}
+	expectedExtra := map[string]interface{}{"build": json.Number("1.10"), "release": "1.10", "flag": false}
+	if !reflect.DeepEqual(result.Extra, expectedExtra) {
+		t.Errorf("Expected any values to be resolved by the YAML rules, got %#v", result.Extra)
+	}
+}
+
+func TestLoad_InvalidYAMLConfigFile(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "config.yaml"), []byte("port: [1"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := New[TestConfig]().WithConfigDirectory(tempDir).Load()
+	if err == nil || !strings.Contains(err.Error(), "failed to load base config: yaml: line 1") {
+		t.Errorf("Expected YAML parse error, got %v", err)
+	}
+}